@@ -0,0 +1,199 @@
+package lotrsdk
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFailureThreshold = 5
+	defaultCooldown         = 30 * time.Second
+	defaultHalfOpenRequests = 1
+)
+
+// ErrCircuitOpen is returned (wrapped) by every call made while the circuit breaker is open;
+// check for it with errors.Is
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets every request through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails every request with ErrCircuitOpen without sending it
+	CircuitOpen CircuitState = iota
+	// CircuitHalfOpen lets a few trial requests through to find out if the API has recovered
+	CircuitHalfOpen CircuitState = iota
+)
+
+func (cs CircuitState) String() string {
+	switch cs {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerOptions configures a CircuitBreaker
+// any zero field uses its default
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of failures in a row that opens the circuit; defaults to 5
+	FailureThreshold int
+	// Cooldown is how long the circuit stays open before letting trial requests through; defaults to 30s
+	Cooldown time.Duration
+	// HalfOpenRequests is the number of trial requests let through while half-open; defaults to 1
+	HalfOpenRequests int
+	// OnStateChange is called (outside of any lock) every time the circuit changes state
+	OnStateChange func(from, to CircuitState)
+}
+
+// CircuitBreaker stops sending requests while the-one-api is failing, so callers get ErrCircuitOpen
+// right away instead of piling up slow timeouts. Network errors, timeouts and 5xx statuses count as
+// failures; every other response (including a 429, which is about quota rather than the API's health)
+// counts as a success. Requests cancelled by their own context count as neither.
+// A single CircuitBreaker may be shared by several Clients.
+type CircuitBreaker struct {
+	mu   sync.Mutex
+	opts CircuitBreakerOptions
+
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	// trials is the number of trial requests in flight while half-open
+	trials int
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker
+//   opts - the thresholds and callbacks
+func NewCircuitBreaker(opts CircuitBreakerOptions) *CircuitBreaker {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = defaultFailureThreshold
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = defaultCooldown
+	}
+	if opts.HalfOpenRequests <= 0 {
+		opts.HalfOpenRequests = defaultHalfOpenRequests
+	}
+	return &CircuitBreaker{
+		opts: opts,
+	}
+}
+
+// WithCircuitBreaker adds breaker.Middleware() to the Client's request chain
+//   breaker - the CircuitBreaker guarding the API
+func WithCircuitBreaker(breaker *CircuitBreaker) ClientOption {
+	return WithMiddleware(breaker.Middleware())
+}
+
+// Middleware returns a Middleware that fails requests with ErrCircuitOpen while the circuit is open
+// it should come before RetryMiddleware in the chain, so retries do not count as separate failures
+func (cb *CircuitBreaker) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if err := cb.allow(); err != nil {
+				return nil, err
+			}
+
+			resp, err := next(req)
+			switch {
+			case err != nil && req.Context().Err() != nil && errors.Is(err, req.Context().Err()):
+				cb.release()
+			case err != nil || resp.StatusCode >= 500:
+				cb.record(false)
+			default:
+				cb.record(true)
+			}
+			return resp, err
+		}
+	}
+}
+
+// State returns the current state of the circuit
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	state, changed := cb.advanceLocked()
+	cb.mu.Unlock()
+
+	cb.notify(changed, CircuitOpen, state)
+	return state
+}
+
+// allow reports whether a request may be sent, taking a trial slot if the circuit is half-open
+func (cb *CircuitBreaker) allow() error {
+	cb.mu.Lock()
+	state, changed := cb.advanceLocked()
+	allowed := true
+	switch state {
+	case CircuitOpen:
+		allowed = false
+	case CircuitHalfOpen:
+		if cb.trials >= cb.opts.HalfOpenRequests {
+			allowed = false
+		} else {
+			cb.trials++
+		}
+	}
+	cb.mu.Unlock()
+
+	cb.notify(changed, CircuitOpen, CircuitHalfOpen)
+	if !allowed {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// advanceLocked moves an open circuit to half-open once the cooldown is over
+// the second return is true if the state changed
+func (cb *CircuitBreaker) advanceLocked() (CircuitState, bool) {
+	if cb.state == CircuitOpen && time.Since(cb.openedAt) >= cb.opts.Cooldown {
+		cb.state = CircuitHalfOpen
+		cb.trials = 0
+		return cb.state, true
+	}
+	return cb.state, false
+}
+
+// release gives back a half-open trial slot for a request that was cancelled by its caller
+func (cb *CircuitBreaker) release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == CircuitHalfOpen && cb.trials > 0 {
+		cb.trials--
+	}
+}
+
+// record updates the circuit with the outcome of a request
+func (cb *CircuitBreaker) record(success bool) {
+	cb.mu.Lock()
+	from := cb.state
+	if success {
+		cb.failures = 0
+		if cb.state == CircuitHalfOpen {
+			cb.state = CircuitClosed
+		}
+	} else {
+		cb.failures++
+		if cb.state == CircuitHalfOpen || (cb.state == CircuitClosed && cb.failures >= cb.opts.FailureThreshold) {
+			cb.state = CircuitOpen
+			cb.openedAt = time.Now()
+		}
+	}
+	to := cb.state
+	cb.mu.Unlock()
+
+	cb.notify(from != to, from, to)
+}
+
+func (cb *CircuitBreaker) notify(changed bool, from, to CircuitState) {
+	if changed && cb.opts.OnStateChange != nil {
+		cb.opts.OnStateChange(from, to)
+	}
+}
@@ -0,0 +1,137 @@
+package lotrsdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newToggleServer answers 503 while failing is set, and a book otherwise
+func newToggleServer(hits, failing *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(hits, 1)
+		if atomic.LoadInt64(failing) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(bookData))
+	}))
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var hits int64
+	failing := int64(1)
+	ts := newToggleServer(&hits, &failing)
+	defer ts.Close()
+
+	var mu sync.Mutex
+	var changes []string
+	breaker := NewCircuitBreaker(CircuitBreakerOptions{
+		FailureThreshold: 2,
+		Cooldown:         50 * time.Millisecond,
+		OnStateChange: func(from, to CircuitState) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, from.String()+"->"+to.String())
+		},
+	})
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithCircuitBreaker(breaker))
+
+	// two failures in a row open the circuit
+	for i := 0; i < 2; i++ {
+		_, _, err := client.Books()
+		assert.NotNil(t, err)
+		assert.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+	assert.Equal(t, breaker.State(), CircuitOpen)
+
+	// while open, calls fail without reaching the server
+	_, _, err := client.Books()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, atomic.LoadInt64(&hits), int64(2))
+
+	// after the cooldown a failing trial reopens the circuit
+	time.Sleep(60 * time.Millisecond)
+	_, _, err = client.Books()
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, breaker.State(), CircuitOpen)
+	assert.Equal(t, atomic.LoadInt64(&hits), int64(3))
+
+	// and a successful trial closes it
+	atomic.StoreInt64(&failing, 0)
+	time.Sleep(60 * time.Millisecond)
+	books, _, err := client.Books()
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+	assert.Equal(t, breaker.State(), CircuitClosed)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, changes, []string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	})
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	var hits int64
+	failing := int64(1)
+	ts := newToggleServer(&hits, &failing)
+	defer ts.Close()
+	breaker := NewCircuitBreaker(CircuitBreakerOptions{FailureThreshold: 2})
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithCircuitBreaker(breaker))
+
+	client.Books()
+	atomic.StoreInt64(&failing, 0)
+	client.Books()
+	atomic.StoreInt64(&failing, 1)
+	client.Books()
+
+	// the failures were not in a row
+	assert.Equal(t, breaker.State(), CircuitClosed)
+}
+
+func TestCircuitBreakerHalfOpenLimit(t *testing.T) {
+	var hits int64
+	release := make(chan struct{})
+	ts := newSlowServer(&hits, release)
+	defer ts.Close()
+	defer close(release)
+
+	breaker := NewCircuitBreaker(CircuitBreakerOptions{FailureThreshold: 1, Cooldown: time.Millisecond})
+	breaker.record(false)
+	time.Sleep(5 * time.Millisecond)
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithCircuitBreaker(breaker))
+
+	// the single trial request is in flight, so a second call is refused
+	go client.Books()
+	waitForHits(&hits, 1)
+	_, _, err := client.Books()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, breaker.State(), CircuitHalfOpen)
+}
+
+func TestCircuitBreakerIgnoresCancelledCalls(t *testing.T) {
+	var hits int64
+	release := make(chan struct{})
+	ts := newSlowServer(&hits, release)
+	defer ts.Close()
+	defer close(release)
+	breaker := NewCircuitBreaker(CircuitBreakerOptions{FailureThreshold: 1})
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithCircuitBreaker(breaker))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := client.Books(WithContext(ctx))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, breaker.State(), CircuitClosed)
+}
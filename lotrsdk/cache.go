@@ -0,0 +1,320 @@
+package lotrsdk
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheMaxEntries     = 1000
+	defaultCacheRefreshTimeout = 30 * time.Second
+)
+
+// CacheOptions configures a Cache
+type CacheOptions struct {
+	// MaxEntries is the number of responses kept; the least recently used is dropped
+	// once there are more. Defaults to 1000
+	MaxEntries int
+
+	// MaxAge is how long a stored response stays fresh; fresh responses are served without
+	// making any request. Defaults to 0, meaning every request is revalidated
+	MaxAge time.Duration
+
+	// StaleWhileRevalidate is how long after MaxAge a stale response is still served right away,
+	// while it is refreshed in the background for the next request
+	StaleWhileRevalidate time.Duration
+
+	// RefreshTimeout bounds a background refresh, so a request that hangs does not stop the
+	// response from ever being refreshed again. Defaults to 30s
+	RefreshTimeout time.Duration
+
+	// StaleIfError is how long after MaxAge a stale response is served in place of an error,
+	// when the request fails with a network error, a 429 (Too Many Requests) or a 5xx status
+	StaleIfError time.Duration
+}
+
+// Cache keeps the last response for every endpoint and filter query (in any order) so that it can
+// be revalidated with a conditional request: when the-one-api sent an ETag or Last-Modified header,
+// the next identical request sends If-None-Match / If-Modified-Since and a 304 (Not Modified)
+// response is answered with the stored body.
+//
+// By default responses without either header are not stored. With MaxAge, StaleWhileRevalidate or
+// StaleIfError set, every successful response is stored, so that it can be served while fresh, or
+// served stale while the API is slow to answer or failing. Stale responses are marked with
+// ResponseMeta.Stale.
+//
+// A Cache is safe for concurrent use and may be shared by several Clients, even ones using different
+// tokens: responses are only served to requests made with the same token.
+type Cache struct {
+	mu         sync.Mutex
+	opts       CacheOptions
+	maxEntries int
+	entries    map[string]*list.Element
+	// lru holds the *cacheEntry values, most recently used first
+	lru *list.List
+	// refreshing holds the keys being refreshed in the background
+	refreshing map[string]bool
+}
+
+type cacheEntry struct {
+	key          string
+	statusCode   int
+	header       http.Header
+	body         []byte
+	etag         string
+	lastModified string
+	storedAt     time.Time
+}
+
+// NewCache creates an empty Cache
+//   opts - the cache settings
+func NewCache(opts CacheOptions) *Cache {
+	maxEntries := opts.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultCacheMaxEntries
+	}
+	if opts.RefreshTimeout <= 0 {
+		opts.RefreshTimeout = defaultCacheRefreshTimeout
+	}
+	return &Cache{
+		opts:       opts,
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+		refreshing: map[string]bool{},
+	}
+}
+
+// WithCache adds cache.Middleware() to the Client's request chain
+//   cache - where to keep the responses
+func WithCache(cache *Cache) ClientOption {
+	return WithMiddleware(cache.Middleware())
+}
+
+// Middleware returns a Middleware that revalidates requests against the Cache
+// it should come before RetryMiddleware in the chain
+func (ca *Cache) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.Method != http.MethodGet {
+				return next(req)
+			}
+			state := getRequestState(req.Context())
+			if state != nil {
+				state.cacheLookup = true
+			}
+
+			key := cacheKey(req)
+			entry := ca.get(key)
+			age := time.Duration(0)
+			if entry != nil {
+				age = time.Since(entry.storedAt)
+			}
+
+			switch {
+			case entry != nil && age < ca.opts.MaxAge:
+				markFromCache(state, false)
+				return entry.response(req), nil
+			case entry != nil && age < ca.opts.MaxAge+ca.opts.StaleWhileRevalidate:
+				ca.refreshInBackground(next, req, key)
+				markFromCache(state, true)
+				return entry.response(req), nil
+			}
+
+			resp, err := ca.revalidate(next, req, key)
+			if entry == nil || age >= ca.opts.MaxAge+ca.opts.StaleIfError || req.Context().Err() != nil {
+				return resp, err
+			}
+			if err == nil {
+				if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+					return resp, nil
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+			markFromCache(state, true)
+			return entry.response(req), nil
+		}
+	}
+}
+
+// markFromCache records on the call's state that its response came from the cache
+func markFromCache(state *requestState, stale bool) {
+	if state != nil {
+		state.fromCache = true
+		state.stale = stale
+	}
+}
+
+// refreshInBackground revalidates the entry for key without making the caller wait
+// only one refresh per key runs at a time, for at most RefreshTimeout
+func (ca *Cache) refreshInBackground(next RoundTripFunc, req *http.Request, key string) {
+	ca.mu.Lock()
+	if ca.refreshing[key] {
+		ca.mu.Unlock()
+		return
+	}
+	ca.refreshing[key] = true
+	ca.mu.Unlock()
+
+	// the refresh must outlive the caller's context, and must not write to the caller's state
+	ctx, cancel := context.WithTimeout(detachedContext{parent: req.Context()}, ca.opts.RefreshTimeout)
+	if state := getRequestState(ctx); state != nil {
+		refreshState := *state
+		ctx = withRequestState(ctx, &refreshState)
+	}
+	refreshReq := req.Clone(ctx)
+
+	go func() {
+		defer func() {
+			cancel()
+			ca.mu.Lock()
+			delete(ca.refreshing, key)
+			ca.mu.Unlock()
+		}()
+		resp, err := ca.revalidate(next, refreshReq, key)
+		if err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}()
+}
+
+// revalidate sends the request, conditional on the stored entry's validators if there is one
+func (ca *Cache) revalidate(next RoundTripFunc, req *http.Request, key string) (*http.Response, error) {
+	entry := ca.get(key)
+	if entry != nil {
+		req = req.Clone(req.Context())
+		if entry.etag != "" {
+			req.Header.Set("If-None-Match", entry.etag)
+		}
+		if entry.lastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.lastModified)
+		}
+	}
+
+	resp, err := next(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		entry = ca.refresh(entry, resp.Header)
+		markFromCache(getRequestState(req.Context()), false)
+		return entry.response(req), nil
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag == "" && lastModified == "" && !ca.storesEverything() {
+			return resp, nil
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		ca.put(&cacheEntry{
+			key:          key,
+			statusCode:   resp.StatusCode,
+			header:       resp.Header.Clone(),
+			body:         body,
+			etag:         etag,
+			lastModified: lastModified,
+			storedAt:     time.Now(),
+		})
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return resp, nil
+}
+
+// storesEverything reports whether responses without validators are stored too
+func (ca *Cache) storesEverything() bool {
+	return ca.opts.MaxAge > 0 || ca.opts.StaleWhileRevalidate > 0 || ca.opts.StaleIfError > 0
+}
+
+// cacheKey identifies the resource a request is for: its path and canonical query, and a hash of the
+// Authorization header, so Clients with different tokens sharing a Cache never see each other's responses
+func cacheKey(req *http.Request) string {
+	auth := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return hex.EncodeToString(auth[:8]) + " " + req.URL.Path + "?" + canonicalQuery(req.URL.RawQuery)
+}
+
+func (ca *Cache) get(key string) *cacheEntry {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	elt, ok := ca.entries[key]
+	if !ok {
+		return nil
+	}
+	ca.lru.MoveToFront(elt)
+	return elt.Value.(*cacheEntry)
+}
+
+func (ca *Cache) put(entry *cacheEntry) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	if elt, ok := ca.entries[entry.key]; ok {
+		elt.Value = entry
+		ca.lru.MoveToFront(elt)
+		return
+	}
+	ca.entries[entry.key] = ca.lru.PushFront(entry)
+	for ca.lru.Len() > ca.maxEntries {
+		oldest := ca.lru.Back()
+		ca.lru.Remove(oldest)
+		delete(ca.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// refresh stores a copy of entry updated with the headers of a 304 response (which may carry a
+// new ETag, Date, rate limit, etc..); entries are never modified in place since they may be in use
+func (ca *Cache) refresh(entry *cacheEntry, header http.Header) *cacheEntry {
+	updated := *entry
+	updated.header = entry.header.Clone()
+	for key, values := range header {
+		if key == "Content-Length" {
+			continue
+		}
+		updated.header[key] = values
+	}
+	if etag := header.Get("ETag"); etag != "" {
+		updated.etag = etag
+	}
+	if lastModified := header.Get("Last-Modified"); lastModified != "" {
+		updated.lastModified = lastModified
+	}
+	updated.storedAt = time.Now()
+	ca.put(&updated)
+	return &updated
+}
+
+// response builds a new response serving the entry's body
+func (ce *cacheEntry) response(req *http.Request) *http.Response {
+	header := ce.header.Clone()
+	header.Set("Content-Length", strconv.Itoa(len(ce.body)))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", ce.statusCode, http.StatusText(ce.statusCode)),
+		StatusCode:    ce.statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(ce.body)),
+		ContentLength: int64(len(ce.body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,341 @@
+package lotrsdk
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// validatingServer stands in for an API that sends validators and honors conditional requests
+type validatingServer struct {
+	mu           sync.Mutex
+	data         string
+	lastModified time.Time
+	useETag      bool
+	useModified  bool
+	// conditional holds the If-None-Match / If-Modified-Since of each request
+	conditional []string
+	statuses    []int
+}
+
+func (vs *validatingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	etag := fmt.Sprintf(`"%x"`, sha1.Sum([]byte(vs.data)))
+	vs.conditional = append(vs.conditional, r.Header.Get("If-None-Match")+r.Header.Get("If-Modified-Since"))
+	if vs.useETag {
+		w.Header().Set("ETag", etag)
+	}
+	if vs.useModified {
+		w.Header().Set("Last-Modified", vs.lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	notModified := false
+	if vs.useETag && r.Header.Get("If-None-Match") == etag {
+		notModified = true
+	} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); vs.useModified && err == nil && !vs.lastModified.Truncate(time.Second).After(since) {
+		notModified = true
+	}
+	if notModified {
+		vs.statuses = append(vs.statuses, http.StatusNotModified)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	vs.statuses = append(vs.statuses, http.StatusOK)
+	w.Write([]byte(vs.data))
+}
+
+func (vs *validatingServer) setData(data string) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	vs.data = data
+	vs.lastModified = time.Now().Add(time.Hour)
+}
+
+func TestCacheETag(t *testing.T) {
+	vs := &validatingServer{data: bookData, useETag: true}
+	ts := httptest.NewServer(vs)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithCache(NewCache(CacheOptions{})))
+
+	var first, second ResponseMeta
+	books, _, err := client.Books(Limit(3), WithResponseMeta(&first))
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+	assert.False(t, first.FromCache)
+
+	books, status, err := client.Books(Limit(3), WithResponseMeta(&second))
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+	assert.Equal(t, books[0].Name, "The Fellowship Of The Ring")
+	assert.Equal(t, status.Total, 1)
+	assert.True(t, second.FromCache)
+	assert.Equal(t, second.StatusCode, http.StatusOK)
+
+	assert.Equal(t, vs.statuses, []int{http.StatusOK, http.StatusNotModified})
+	assert.Equal(t, vs.conditional[0], "")
+	assert.True(t, strings.HasPrefix(vs.conditional[1], `"`))
+
+	// once the data changes the new body is returned and stored
+	vs.setData(strings.Replace(bookData, "Fellowship", "Fellowship (extended)", 1))
+	books, _, err = client.Books(Limit(3))
+	assert.Nil(t, err)
+	assert.Equal(t, books[0].Name, "The Fellowship (extended) Of The Ring")
+	books, _, err = client.Books(Limit(3))
+	assert.Nil(t, err)
+	assert.Equal(t, books[0].Name, "The Fellowship (extended) Of The Ring")
+	assert.Equal(t, vs.statuses[2:], []int{http.StatusOK, http.StatusNotModified})
+}
+
+func TestCacheLastModified(t *testing.T) {
+	vs := &validatingServer{data: bookData, useModified: true, lastModified: time.Now().Add(-time.Hour)}
+	ts := httptest.NewServer(vs)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithCache(NewCache(CacheOptions{})))
+
+	client.Books()
+	books, _, err := client.Books()
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+	assert.Equal(t, vs.statuses, []int{http.StatusOK, http.StatusNotModified})
+	_, err = http.ParseTime(vs.conditional[1])
+	assert.Nil(t, err)
+}
+
+func TestCacheWithoutValidators(t *testing.T) {
+	vs := &validatingServer{data: bookData}
+	ts := httptest.NewServer(vs)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithCache(NewCache(CacheOptions{})))
+
+	for i := 0; i < 3; i++ {
+		books, _, err := client.Books()
+		assert.Nil(t, err)
+		assert.Equal(t, len(books), 1)
+	}
+
+	assert.Equal(t, vs.conditional, []string{"", "", ""})
+	assert.Equal(t, vs.statuses, []int{http.StatusOK, http.StatusOK, http.StatusOK})
+}
+
+func TestCacheKeyAndEviction(t *testing.T) {
+	vs := &validatingServer{data: bookData, useETag: true}
+	ts := httptest.NewServer(vs)
+	defer ts.Close()
+	cache := NewCache(CacheOptions{MaxEntries: 1})
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithCache(cache))
+
+	client.Books(Limit(3), Page(1))
+	client.Books(Page(1), Limit(3))
+	client.Movies()
+	client.Books(Limit(3), Page(1))
+
+	// the filter order does not matter, but the movie response pushed out the book one
+	assert.Equal(t, vs.statuses, []int{http.StatusOK, http.StatusNotModified, http.StatusOK, http.StatusOK})
+}
+
+func TestCacheSharedAcrossTokens(t *testing.T) {
+	vs := &validatingServer{data: bookData, useETag: true}
+	ts := httptest.NewServer(vs)
+	defer ts.Close()
+	cache := NewCache(CacheOptions{MaxAge: time.Hour})
+	first := NewClient("first-token", WithBaseURL(ts.URL), WithCache(cache))
+	second := NewClient("second-token", WithBaseURL(ts.URL), WithCache(cache))
+
+	first.Books()
+	second.Books()
+	first.Books()
+
+	// the second token gets its own response; the first token's is still fresh
+	assert.Equal(t, vs.statuses, []int{http.StatusOK, http.StatusOK})
+	assert.Equal(t, vs.conditional, []string{"", ""})
+}
+
+func TestCacheMetrics(t *testing.T) {
+	vs := &validatingServer{data: bookData, useETag: true}
+	ts := httptest.NewServer(vs)
+	defer ts.Close()
+	metrics := NewMetrics()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithMetrics(metrics), WithCache(NewCache(CacheOptions{})))
+
+	client.Books()
+	client.Books()
+	client.Books()
+
+	var sb strings.Builder
+	metrics.WriteTo(&sb)
+	assert.Contains(t, sb.String(), `lotrsdk_cache_requests_total{resource="book",result="hit"} 2`)
+	assert.Contains(t, sb.String(), `lotrsdk_cache_requests_total{resource="book",result="miss"} 1`)
+}
+
+// flakyServer answers with bookData until failing is set, then with status (or by dropping the connection if status is 0)
+type flakyServer struct {
+	mu      sync.Mutex
+	hits    int
+	failing bool
+	status  int
+	data    string
+}
+
+func (fs *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.hits++
+	if !fs.failing {
+		w.Write([]byte(fs.data))
+		return
+	}
+	if fs.status == 0 {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+		return
+	}
+	w.WriteHeader(fs.status)
+}
+
+func (fs *flakyServer) set(failing bool, status int, data string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.failing, fs.status, fs.data = failing, status, data
+}
+
+func (fs *flakyServer) hitCount() int {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.hits
+}
+
+func TestCacheMaxAge(t *testing.T) {
+	fs := &flakyServer{data: bookData}
+	ts := httptest.NewServer(fs)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithCache(NewCache(CacheOptions{MaxAge: time.Hour})))
+
+	client.Books()
+	var meta ResponseMeta
+	books, _, err := client.Books(WithResponseMeta(&meta))
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+	assert.True(t, meta.FromCache)
+	assert.False(t, meta.Stale)
+	assert.Equal(t, fs.hitCount(), 1)
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	fs := &flakyServer{data: bookData}
+	ts := httptest.NewServer(fs)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithCache(NewCache(CacheOptions{StaleWhileRevalidate: time.Hour})))
+
+	client.Books()
+	fs.set(false, 0, strings.Replace(bookData, "Fellowship", "Fellowship (extended)", 1))
+
+	// the stale body is served right away, and refreshed in the background
+	var meta ResponseMeta
+	books, _, err := client.Books(WithResponseMeta(&meta))
+	assert.Nil(t, err)
+	assert.Equal(t, books[0].Name, "The Fellowship Of The Ring")
+	assert.True(t, meta.FromCache)
+	assert.True(t, meta.Stale)
+
+	for fs.hitCount() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Eventually(t, func() bool {
+		books, _, err := client.Books()
+		return err == nil && books[0].Name == "The Fellowship (extended) Of The Ring"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestCacheRefreshTimeout(t *testing.T) {
+	var hits int64
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// every request after the first hangs
+		if atomic.AddInt64(&hits, 1) > 1 {
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+			return
+		}
+		w.Write([]byte(bookData))
+	}))
+	defer ts.Close()
+	defer close(release)
+	cache := NewCache(CacheOptions{StaleWhileRevalidate: time.Hour, RefreshTimeout: 20 * time.Millisecond})
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithCache(cache))
+
+	client.Books()
+	_, _, err := client.Books()
+	assert.Nil(t, err)
+
+	// once the hung refresh times out, the next stale response starts another one
+	assert.Eventually(t, func() bool {
+		_, _, err := client.Books()
+		return err == nil && atomic.LoadInt64(&hits) >= 3
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestCacheStaleIfError(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, 0} {
+		fs := &flakyServer{data: bookData}
+		ts := httptest.NewServer(fs)
+		client := NewClient("fake-token", WithBaseURL(ts.URL), WithCache(NewCache(CacheOptions{StaleIfError: time.Hour})))
+
+		_, _, err := client.Books()
+		assert.Nil(t, err)
+		fs.set(true, status, "")
+
+		var meta ResponseMeta
+		books, _, err := client.Books(WithResponseMeta(&meta))
+		assert.Nil(t, err, "status %d", status)
+		assert.Equal(t, len(books), 1)
+		assert.True(t, meta.Stale)
+		// net/http retries a GET once by itself when a reused connection is dropped
+		assert.GreaterOrEqual(t, fs.hitCount(), 2)
+
+		// without a stored response the error still comes through
+		_, _, err = client.Movies()
+		assert.NotNil(t, err)
+		ts.Close()
+	}
+}
+
+func TestCacheStaleIfErrorExpired(t *testing.T) {
+	fs := &flakyServer{data: bookData}
+	ts := httptest.NewServer(fs)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithCache(NewCache(CacheOptions{StaleIfError: time.Millisecond})))
+
+	client.Books()
+	fs.set(true, http.StatusServiceUnavailable, "")
+	time.Sleep(5 * time.Millisecond)
+
+	_, _, err := client.Books()
+	assert.NotNil(t, err)
+}
+
+func TestCacheStaleIgnoresClientErrors(t *testing.T) {
+	fs := &flakyServer{data: bookData}
+	ts := httptest.NewServer(fs)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithCache(NewCache(CacheOptions{StaleIfError: time.Hour})))
+
+	client.Books()
+	fs.set(true, http.StatusUnauthorized, "")
+
+	_, _, err := client.Books()
+	assert.NotNil(t, err)
+}
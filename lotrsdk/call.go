@@ -0,0 +1,55 @@
+package lotrsdk
+
+import (
+	"context"
+)
+
+// Some options only change how a single call is made rather than what is requested
+// (ie, which context.Context the request uses). To keep the Client methods simple these
+// are passed in alongside the other filters, the same way Sort and pagination are.
+// They never add anything to the query params.
+
+// callConfig holds the per-call settings gathered from the call options
+type callConfig struct {
+	ctx  context.Context
+	meta *ResponseMeta
+	// priority is nil unless WithPriority was used
+	priority *Priority
+}
+
+// callOption is a Filter that modifies the callConfig of a single call
+type callOption func(*callConfig)
+
+func (co callOption) GenerateRawQuery() (string, error) {
+	return "", nil
+}
+
+// WithContext makes the call use the provided context, so it can be cancelled
+// or given a deadline. Calls without this option use context.Background().
+//   ctx - the context for the request
+func WithContext(ctx context.Context) Filter {
+	return callOption(func(cfg *callConfig) {
+		cfg.ctx = ctx
+	})
+}
+
+// newCallConfig collects the call options out of the provided filters
+// (including filters nested via MergeFilters)
+func newCallConfig(filters ...Filter) *callConfig {
+	cfg := &callConfig{
+		ctx: context.Background(),
+	}
+	applyCallOptions(cfg, filters)
+	return cfg
+}
+
+func applyCallOptions(cfg *callConfig, filters []Filter) {
+	for _, f := range filters {
+		switch opt := f.(type) {
+		case callOption:
+			opt(cfg)
+		case Filters:
+			applyCallOptions(cfg, opt)
+		}
+	}
+}
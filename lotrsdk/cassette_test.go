@@ -0,0 +1,97 @@
+package lotrsdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	var hits int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&hits, 1)
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "99")
+		w.Write([]byte(bookData))
+	}))
+	defer ts.Close()
+	path := filepath.Join(t.TempDir(), "cassettes", "books.json")
+
+	recorder, err := NewCassette(path, CassetteRecord, nil)
+	assert.Nil(t, err)
+	client := NewClient("secret-token", WithBaseURL(ts.URL), WithHTTPClient(&http.Client{Transport: recorder}))
+	books, _, err := client.Books(Limit(5), Sort("name", SortOrderAscending))
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+	assert.Equal(t, len(recorder.Interactions()), 1)
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(string(data), "secret-token"))
+	assert.False(t, strings.Contains(string(data), "Authorization"))
+
+	// replaying never reaches the server, and matches filters in any order
+	ts.Close()
+	player, err := NewCassette(path, CassetteReplay, nil)
+	assert.Nil(t, err)
+	client = NewClient("other-token", WithBaseURL(ts.URL), WithHTTPClient(&http.Client{Transport: player}))
+	meta := &ResponseMeta{}
+	books, _, err = client.Books(Sort("name", SortOrderAscending), Limit(5), WithResponseMeta(meta))
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+	assert.Equal(t, books[0].Name, "The Fellowship Of The Ring")
+	assert.Equal(t, meta.RateLimit.Remaining, 99)
+	assert.Equal(t, atomic.LoadInt64(&hits), int64(1))
+
+	_, _, err = client.Books(Limit(6))
+	assert.ErrorIs(t, err, ErrNoRecording)
+	assert.True(t, strings.Contains(err.Error(), "limit=6"))
+}
+
+func TestCassetteReplaysInOrder(t *testing.T) {
+	var hits int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(bookData))
+	}))
+	defer ts.Close()
+	path := filepath.Join(t.TempDir(), "books.json")
+
+	recorder, _ := NewCassette(path, CassetteRecord, nil)
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithHTTPClient(&http.Client{Transport: recorder}))
+	client.Books()
+	client.Books()
+
+	player, _ := NewCassette(path, CassetteReplay, nil)
+	client = NewClient("fake-token", WithBaseURL(ts.URL), WithHTTPClient(&http.Client{Transport: player}))
+	_, _, err := client.Books()
+	assert.NotNil(t, err)
+	for i := 0; i < 2; i++ {
+		books, _, err := client.Books()
+		assert.Nil(t, err)
+		assert.Equal(t, len(books), 1)
+	}
+}
+
+func TestCassetteMissingFile(t *testing.T) {
+	_, err := NewCassette(filepath.Join(t.TempDir(), "missing.json"), CassetteReplay, nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRecordRequestRedactsSecrets(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://the-one-api.dev/v2/book?name=Frodo&api_key=hunter2", nil)
+	assert.Equal(t, recordRequest(req), RecordedRequest{
+		Method: "GET",
+		Path:   "/v2/book",
+		Query:  "api_key=REDACTED&name=Frodo",
+	})
+}
@@ -0,0 +1,391 @@
+package lotrsdk
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// this file contains the parsing of Character's free text fields (Birth, Death and Height)
+
+// ErrUnknownValue is returned when a field holds one of the values the-one-api uses to mean "unknown"
+var ErrUnknownValue = errors.New("value is unknown")
+
+// ParseError is returned when a field's free text can not be read
+type ParseError struct {
+	// Kind is what the value was parsed as ("date" or "height")
+	Kind  string
+	Value string
+}
+
+func (pe *ParseError) Error() string {
+	return fmt.Sprintf("cannot parse %q as a %s", pe.Value, pe.Kind)
+}
+
+// Age is an age (era) of Middle-earth
+type Age int
+
+const (
+	YearsOfTheTrees Age = iota + 1
+	FirstAge
+	SecondAge
+	ThirdAge
+	FourthAge
+)
+
+// ageLengths is roughly how many years each age lasted, used to place dates with no year (such as
+// "Late, First Age") and to count years across ages; the Fourth Age has no recorded end
+var ageLengths = map[Age]int{
+	YearsOfTheTrees: 1500,
+	FirstAge:        590,
+	SecondAge:       3441,
+	ThirdAge:        3021,
+	FourthAge:       1000,
+}
+
+func (a Age) String() string {
+	switch a {
+	case YearsOfTheTrees:
+		return "Years of the Trees"
+	case FirstAge:
+		return "First Age"
+	case SecondAge:
+		return "Second Age"
+	case ThirdAge:
+		return "Third Age"
+	case FourthAge:
+		return "Fourth Age"
+	}
+	return "Unknown Age"
+}
+
+// Abbreviation returns the abbreviation used in dates (YT, FA, SA, TA or FO)
+func (a Age) Abbreviation() string {
+	switch a {
+	case YearsOfTheTrees:
+		return "YT"
+	case FirstAge:
+		return "FA"
+	case SecondAge:
+		return "SA"
+	case ThirdAge:
+		return "TA"
+	case FourthAge:
+		return "FO"
+	}
+	return "?"
+}
+
+// DateQualifier is the part of a date that places it relative to its year or age
+type DateQualifier int
+
+const (
+	// DateExact is a date with no qualifier ("TA 2931")
+	DateExact DateQualifier = iota
+	// DateEarly is the early part of the year's age ("Early, Third Age")
+	DateEarly
+	// DateMid is the middle of the age ("Mid, First Age")
+	DateMid
+	// DateLate is the late part of the age ("Late, First Age")
+	DateLate
+	// DateBefore is some time before the date ("Before, TA 1980")
+	DateBefore
+	// DateAfter is some time after the date ("After SA 3441")
+	DateAfter
+)
+
+func (dq DateQualifier) String() string {
+	switch dq {
+	case DateEarly:
+		return "Early"
+	case DateMid:
+		return "Mid"
+	case DateLate:
+		return "Late"
+	case DateBefore:
+		return "Before"
+	case DateAfter:
+		return "After"
+	}
+	return ""
+}
+
+// MiddleEarthDate is a date parsed from the free text of Character.Birth or Character.Death
+type MiddleEarthDate struct {
+	Age Age
+	// Year is the year within the age, if HasYear
+	Year    int
+	HasYear bool
+	// Qualifier places the date relative to Year or, with no year, within the Age
+	Qualifier DateQualifier
+	// Approximate is true for dates given as "c.", "around", "early", "late", etc..
+	Approximate bool
+	// Uncertain is true for dates given with a question mark, or as alternatives ("FA 455 or FA 456")
+	Uncertain bool
+}
+
+func (d MiddleEarthDate) String() string {
+	parts := make([]string, 0, 3)
+	if d.Qualifier != DateExact {
+		parts = append(parts, d.Qualifier.String())
+	} else if d.Approximate {
+		parts = append(parts, "c.")
+	}
+	if d.HasYear {
+		parts = append(parts, fmt.Sprintf("%s %d", d.Age.Abbreviation(), d.Year))
+	} else {
+		parts = append(parts, d.Age.String())
+	}
+	s := strings.Join(parts, " ")
+	if d.Uncertain {
+		s += "?"
+	}
+	return s
+}
+
+// position returns roughly how many years into its age the date is, for sorting
+func (d MiddleEarthDate) position() float64 {
+	length := float64(ageLengths[d.Age])
+	if !d.HasYear {
+		switch d.Qualifier {
+		case DateEarly:
+			return 0.2 * length
+		case DateMid:
+			return 0.5 * length
+		case DateLate:
+			return 0.8 * length
+		case DateAfter:
+			return length
+		}
+		return 0
+	}
+	switch d.Qualifier {
+	case DateBefore:
+		return float64(d.Year) - 0.5
+	case DateAfter:
+		return float64(d.Year) + 0.5
+	}
+	return float64(d.Year)
+}
+
+// Compare returns -1 if d is before other, 1 if it is after, and 0 if they can not be told apart
+// dates with no year are placed by their qualifier within their age ("Late, First Age" is near FA 470)
+//   other - the date compared with
+func (d MiddleEarthDate) Compare(other MiddleEarthDate) int {
+	if d.Age != other.Age {
+		if d.Age < other.Age {
+			return -1
+		}
+		return 1
+	}
+	p, q := d.position(), other.position()
+	switch {
+	case p < q:
+		return -1
+	case p > q:
+		return 1
+	}
+	return 0
+}
+
+// Before reports whether d is before other
+//   other - the date compared with
+func (d MiddleEarthDate) Before(other MiddleEarthDate) bool {
+	return d.Compare(other) < 0
+}
+
+var (
+	// ageMention matches an age, optionally followed by a year: "TA 2931", "ta2931", "Third Age", "FO 61"
+	ageMention  = regexp.MustCompile(`\b(yt|fa|sa|ta|foa|fo|4a|years of (?:the )?trees|first age|second age|third age|fourth age)\s*(\d+)?\b`)
+	parenthesis = regexp.MustCompile(`\([^)]*\)`)
+)
+
+var ageNames = map[string]Age{
+	"yt":                 YearsOfTheTrees,
+	"years of the trees": YearsOfTheTrees,
+	"years of trees":     YearsOfTheTrees,
+	"fa":                 FirstAge,
+	"first age":          FirstAge,
+	"sa":                 SecondAge,
+	"second age":         SecondAge,
+	"ta":                 ThirdAge,
+	"third age":          ThirdAge,
+	"fo":                 FourthAge,
+	"foa":                FourthAge,
+	"4a":                 FourthAge,
+	"fourth age":         FourthAge,
+}
+
+var qualifierWords = map[string]DateQualifier{
+	"early":  DateEarly,
+	"mid":    DateMid,
+	"middle": DateMid,
+	"late":   DateLate,
+	"before": DateBefore,
+	"after":  DateAfter,
+}
+
+var approximateWords = map[string]bool{
+	"c":             true,
+	"ca":            true,
+	"circa":         true,
+	"around":        true,
+	"about":         true,
+	"approx":        true,
+	"approximately": true,
+	"~":             true,
+}
+
+// dateFillerWords may come before a date without changing it ("In the Third Age", "March 1, TA 3019")
+var dateFillerWords = map[string]bool{
+	"in": true, "the": true, "of": true, "year": true, "on": true,
+	"january": true, "february": true, "march": true, "april": true, "may": true, "june": true,
+	"july": true, "august": true, "september": true, "october": true, "november": true, "december": true,
+}
+
+// isDayOfMonth reports whether word is a number from 1 to 31
+func isDayOfMonth(word string) bool {
+	day, err := strconv.Atoi(word)
+	return err == nil && day >= 1 && day <= 31
+}
+
+// ParseMiddleEarthDate parses the free text of a Character's Birth or Death, such as "TA 2931",
+// "Late ,First Age", "FA 121", "c. SA 3200" or "FA 455 or FA 456"
+// returns ErrUnknownValue for the values meaning "unknown", and a *ParseError if s can not be read
+//   s - the free text date
+func ParseMiddleEarthDate(s string) (MiddleEarthDate, error) {
+	// notes in parenthesis ("TA 3019 (sailed West)", "Unknown (Last sighting ...)") do not change the date
+	text := strings.ToLower(parenthesis.ReplaceAllString(s, " "))
+	if IsUnknown(text) || strings.TrimSpace(text) == "unknown" {
+		return MiddleEarthDate{}, ErrUnknownValue
+	}
+	text = strings.NewReplacer(",", " ", ";", " ", "-", " ").Replace(text)
+	text = strings.Join(strings.Fields(text), " ")
+
+	mentions := ageMention.FindAllStringSubmatchIndex(text, -1)
+	if len(mentions) == 0 {
+		return MiddleEarthDate{}, &ParseError{Kind: "date", Value: s}
+	}
+
+	first := mentions[0]
+	date := MiddleEarthDate{Age: ageNames[text[first[2]:first[3]]]}
+	if first[4] >= 0 {
+		date.Year, _ = strconv.Atoi(text[first[4]:first[5]])
+		date.HasYear = true
+	}
+
+	for _, word := range strings.Fields(strings.ReplaceAll(text[:first[0]], ".", " ")) {
+		if qualifier, ok := qualifierWords[word]; ok {
+			date.Qualifier = qualifier
+			date.Approximate = true
+		} else if approximateWords[word] {
+			date.Approximate = true
+		} else if word == "probably" || word == "possibly" {
+			date.Uncertain = true
+		} else if !dateFillerWords[word] && !isDayOfMonth(word) {
+			return MiddleEarthDate{}, &ParseError{Kind: "date", Value: s}
+		}
+	}
+	// a later mention of the same age may give the year ("Fourth Age ,FO 120"); any other is an
+	// alternative or a range ("FA 455 or FA 456", "TA 2978 ,TA 2979")
+	for _, mention := range mentions[1:] {
+		age := ageNames[text[mention[2]:mention[3]]]
+		year, hasYear := 0, mention[4] >= 0
+		if hasYear {
+			year, _ = strconv.Atoi(text[mention[4]:mention[5]])
+		}
+		switch {
+		case age != date.Age:
+			date.Uncertain = true
+		case hasYear && !date.HasYear:
+			date.Year, date.HasYear = year, true
+		case hasYear && year != date.Year:
+			date.Uncertain = true
+		}
+	}
+	if !date.HasYear && date.Qualifier == DateExact {
+		// a bare age ("Third Age") is some time within it
+		date.Approximate = true
+	}
+	if strings.Contains(text, "?") {
+		date.Uncertain = true
+	}
+	return date, nil
+}
+
+// Height is a height parsed from the free text of Character.Height
+type Height struct {
+	Metres float64
+	// Approximate is true for heights given as "about", "over", "under", etc..
+	Approximate bool
+}
+
+var (
+	metresHeight = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*(cm|centimetres|centimeters|m|metres|meters|metre|meter)\b`)
+	feetHeight   = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*(?:'|ft\b|feet\b|foot\b)\s*(?:(\d+(?:\.\d+)?)\s*(?:"|''|in\b|inches\b))?`)
+	inchesHeight = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*(?:"|in\b|inches\b)`)
+	approxHeight = regexp.MustCompile(`\b(about|around|approx|approximately|over|under|at least|nearly|almost|c|ca)\b|~|\?`)
+)
+
+// ParseHeight parses the free text of a Character's Height into metres, such as "1.98m (6'6")", "6'6"",
+// "198 cm" or "Over 6 ft"; metric values are preferred over imperial ones when both are given
+// returns ErrUnknownValue for the values meaning "unknown", and a *ParseError if s can not be read (such as "Tall")
+//   s - the free text height
+func ParseHeight(s string) (Height, error) {
+	if IsUnknown(s) || strings.EqualFold(strings.TrimSpace(s), "unknown") {
+		return Height{}, ErrUnknownValue
+	}
+	text := strings.ToLower(strings.ReplaceAll(s, ",", "."))
+	// curly quotes are common in copied text
+	text = strings.NewReplacer("’", "'", "′", "'", "”", `"`, "″", `"`).Replace(text)
+	height := Height{Approximate: approxHeight.MatchString(text)}
+
+	if match := metresHeight.FindStringSubmatch(text); match != nil {
+		value, _ := strconv.ParseFloat(match[1], 64)
+		if strings.HasPrefix(match[2], "c") {
+			value /= 100
+		}
+		height.Metres = value
+		return height, nil
+	}
+	if match := feetHeight.FindStringSubmatch(text); match != nil {
+		feet, _ := strconv.ParseFloat(match[1], 64)
+		inches := 0.0
+		if match[2] != "" {
+			inches, _ = strconv.ParseFloat(match[2], 64)
+		}
+		height.Metres = roundCentimetres((feet*12 + inches) * 0.0254)
+		return height, nil
+	}
+	if match := inchesHeight.FindStringSubmatch(text); match != nil {
+		inches, _ := strconv.ParseFloat(match[1], 64)
+		height.Metres = roundCentimetres(inches * 0.0254)
+		return height, nil
+	}
+	return Height{}, &ParseError{Kind: "height", Value: s}
+}
+
+// roundCentimetres rounds metres to the centimetre, which is as precise as imperial heights get
+func roundCentimetres(metres float64) float64 {
+	return float64(int(metres*100+0.5)) / 100
+}
+
+// BirthDate parses Birth
+// returns ErrUnknownValue if the birth is unknown, and a *ParseError if it can not be read
+func (c Character) BirthDate() (MiddleEarthDate, error) {
+	return ParseMiddleEarthDate(c.Birth)
+}
+
+// DeathDate parses Death
+// returns ErrUnknownValue if the death is unknown, and a *ParseError if it can not be read
+func (c Character) DeathDate() (MiddleEarthDate, error) {
+	return ParseMiddleEarthDate(c.Death)
+}
+
+// ParsedHeight parses Height
+// returns ErrUnknownValue if the height is unknown, and a *ParseError if it can not be read
+func (c Character) ParsedHeight() (Height, error) {
+	return ParseHeight(c.Height)
+}
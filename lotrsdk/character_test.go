@@ -0,0 +1,174 @@
+package lotrsdk
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMiddleEarthDate(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected MiddleEarthDate
+	}{
+		{"TA 2931", MiddleEarthDate{Age: ThirdAge, Year: 2931, HasYear: true}},
+		{"FA 121", MiddleEarthDate{Age: FirstAge, Year: 121, HasYear: true}},
+		{"SA 3441", MiddleEarthDate{Age: SecondAge, Year: 3441, HasYear: true}},
+		{"YT 1050", MiddleEarthDate{Age: YearsOfTheTrees, Year: 1050, HasYear: true}},
+		{"FO 61", MiddleEarthDate{Age: FourthAge, Year: 61, HasYear: true}},
+		{"FoA 120", MiddleEarthDate{Age: FourthAge, Year: 120, HasYear: true}},
+		{"ta2890", MiddleEarthDate{Age: ThirdAge, Year: 2890, HasYear: true}},
+		{"  TA 3019 ", MiddleEarthDate{Age: ThirdAge, Year: 3019, HasYear: true}},
+		{"TA 3021 (sailed into the West)", MiddleEarthDate{Age: ThirdAge, Year: 3021, HasYear: true}},
+		{"Third Age", MiddleEarthDate{Age: ThirdAge, Approximate: true}},
+		{"Late ,First Age", MiddleEarthDate{Age: FirstAge, Qualifier: DateLate, Approximate: true}},
+		{"Mid ,First Age", MiddleEarthDate{Age: FirstAge, Qualifier: DateMid, Approximate: true}},
+		{"Early ,Third Age", MiddleEarthDate{Age: ThirdAge, Qualifier: DateEarly, Approximate: true}},
+		{"Before ,TA 1980", MiddleEarthDate{Age: ThirdAge, Year: 1980, HasYear: true, Qualifier: DateBefore, Approximate: true}},
+		{"After SA 3441", MiddleEarthDate{Age: SecondAge, Year: 3441, HasYear: true, Qualifier: DateAfter, Approximate: true}},
+		{"c. TA 2800", MiddleEarthDate{Age: ThirdAge, Year: 2800, HasYear: true, Approximate: true}},
+		{"Around SA 3200", MiddleEarthDate{Age: SecondAge, Year: 3200, HasYear: true, Approximate: true}},
+		{"~TA 1000", MiddleEarthDate{Age: ThirdAge, Year: 1000, HasYear: true, Approximate: true}},
+		{"FA 455 or FA 456", MiddleEarthDate{Age: FirstAge, Year: 455, HasYear: true, Uncertain: true}},
+		{"TA 2978 ,TA 2979", MiddleEarthDate{Age: ThirdAge, Year: 2978, HasYear: true, Uncertain: true}},
+		{"TA 2460?", MiddleEarthDate{Age: ThirdAge, Year: 2460, HasYear: true, Uncertain: true}},
+		{"Probably FA 500", MiddleEarthDate{Age: FirstAge, Year: 500, HasYear: true, Uncertain: true}},
+		{"Year of the Trees 1300", MiddleEarthDate{}},
+		{"In the Third Age", MiddleEarthDate{Age: ThirdAge, Approximate: true}},
+		{"Fourth Age ,FO 120", MiddleEarthDate{Age: FourthAge, Year: 120, HasYear: true}},
+		{"Late ,Third Age ,TA 3000", MiddleEarthDate{Age: ThirdAge, Year: 3000, HasYear: true, Qualifier: DateLate, Approximate: true}},
+		{"SA 3441 or TA 2", MiddleEarthDate{Age: SecondAge, Year: 3441, HasYear: true, Uncertain: true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			date, err := ParseMiddleEarthDate(test.value)
+			if test.expected == (MiddleEarthDate{}) {
+				var parseErr *ParseError
+				assert.True(t, errors.As(err, &parseErr))
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, date, test.expected)
+		})
+	}
+}
+
+func TestParseMiddleEarthDateErrors(t *testing.T) {
+	for _, value := range []string{"", "NaN", "  ", "Unknown", "Unknown (Last sighting ,September 29 ,3021,) (,SR 1421,)"} {
+		_, err := ParseMiddleEarthDate(value)
+		assert.ErrorIs(t, err, ErrUnknownValue, value)
+	}
+
+	for _, value := range []string{"Still alive", "1200", "Spring", "Est. 1200", "Fading"} {
+		_, err := ParseMiddleEarthDate(value)
+		var parseErr *ParseError
+		if assert.True(t, errors.As(err, &parseErr), value) {
+			assert.Equal(t, parseErr.Kind, "date")
+			assert.Equal(t, parseErr.Value, value)
+		}
+	}
+}
+
+func TestMiddleEarthDateString(t *testing.T) {
+	tests := map[string]string{
+		"TA 2931":          "TA 2931",
+		"Late ,First Age":  "Late First Age",
+		"c. TA 2800":       "c. TA 2800",
+		"Before ,TA 1980":  "Before TA 1980",
+		"FA 455 or FA 456": "FA 455?",
+		"Third Age":        "c. Third Age",
+	}
+	for value, expected := range tests {
+		date, err := ParseMiddleEarthDate(value)
+		assert.Nil(t, err)
+		assert.Equal(t, date.String(), expected)
+	}
+}
+
+func TestMiddleEarthDateSorting(t *testing.T) {
+	values := []string{"TA 3019", "FO 61", "Late ,First Age", "FA 121", "Before ,TA 3019", "YT 1050", "SA 3441", "Early ,Third Age", "Mid ,First Age", "FA 500"}
+	dates := make([]MiddleEarthDate, len(values))
+	for i, value := range values {
+		date, err := ParseMiddleEarthDate(value)
+		assert.Nil(t, err)
+		dates[i] = date
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	sorted := make([]string, len(dates))
+	for i, date := range dates {
+		sorted[i] = date.String()
+	}
+	assert.Equal(t, sorted, []string{
+		"YT 1050", "FA 121", "Mid First Age", "Late First Age", "FA 500", "SA 3441", "Early Third Age", "Before TA 3019", "TA 3019", "FO 61",
+	})
+
+	a, _ := ParseMiddleEarthDate("TA 3019")
+	b, _ := ParseMiddleEarthDate("c. TA 3019")
+	assert.Equal(t, a.Compare(b), 0)
+}
+
+func TestParseHeight(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Height
+	}{
+		{`1.98m (6'6")`, Height{Metres: 1.98}},
+		{"1.22 m", Height{Metres: 1.22}},
+		{"198cm", Height{Metres: 1.98}},
+		{"2 metres", Height{Metres: 2}},
+		{"1,35m", Height{Metres: 1.35}},
+		{`6'6"`, Height{Metres: 1.98}},
+		{"6’6”", Height{Metres: 1.98}},
+		{"5 ft 7 in", Height{Metres: 1.70}},
+		{"4 feet", Height{Metres: 1.22}},
+		{"Over 6 ft", Height{Metres: 1.83, Approximate: true}},
+		{"about 1.6m", Height{Metres: 1.6, Approximate: true}},
+		{`42"`, Height{Metres: 1.07}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			height, err := ParseHeight(test.value)
+			assert.Nil(t, err)
+			assert.InDelta(t, height.Metres, test.expected.Metres, 0.001)
+			assert.Equal(t, height.Approximate, test.expected.Approximate)
+		})
+	}
+}
+
+func TestParseHeightErrors(t *testing.T) {
+	for _, value := range []string{"", "NaN", "unknown"} {
+		_, err := ParseHeight(value)
+		assert.ErrorIs(t, err, ErrUnknownValue, value)
+	}
+	for _, value := range []string{"Tall", "Short", "Very tall (for a hobbit)"} {
+		_, err := ParseHeight(value)
+		var parseErr *ParseError
+		if assert.True(t, errors.As(err, &parseErr), value) {
+			assert.Equal(t, parseErr.Kind, "height")
+		}
+	}
+}
+
+func TestCharacterAccessors(t *testing.T) {
+	character := Character{Name: "Aragorn II Elessar", Birth: "March 1 ,TA 2931", Death: "FO 120", Height: `198cm (6'6")`}
+
+	birth, err := character.BirthDate()
+	assert.Nil(t, err)
+	assert.Equal(t, birth.String(), "TA 2931")
+	death, err := character.DeathDate()
+	assert.Nil(t, err)
+	assert.True(t, birth.Before(death))
+	height, err := character.ParsedHeight()
+	assert.Nil(t, err)
+	assert.InDelta(t, height.Metres, 1.98, 0.001)
+
+	_, err = Character{Birth: "NaN"}.BirthDate()
+	assert.ErrorIs(t, err, ErrUnknownValue)
+}
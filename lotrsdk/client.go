@@ -1,44 +1,58 @@
 package lotrsdk
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
 const (
 	apiURL = "https://the-one-api.dev/v2"
+
+	// connection pool tuning for the default transport; the stock http.DefaultTransport
+	// only keeps 2 idle connections per host, which forces fan-out callers to keep
+	// redoing the TLS handshake
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 64
+	defaultIdleConnTimeout     = 90 * time.Second
 )
 
 // Client exposes several methods to access the-one-api
 // each method return a slice of the type it was searching for, the response status, and an error
+//
+// A Client is safe for concurrent use by multiple goroutines; all calls share a single
+// underlying *http.Client (and therefore its connection pool), so a Client should be created
+// once and reused rather than created per request.
 type Client interface {
 	// Books retrieves all the LOTR books
 	//   filter - any number of Filter objects
 	Books(filter ...Filter) ([]Book, Status, error)
 
 	// ChapterFromBook retrieves all the chapters from the provided book
-	//   book - the book from which to get the chapters
+	//   book - the book from which to get the chapters, as a BookID or a *Book
 	//   filter - any number of filters objects
-	ChapterFromBook(book *Book, filter ...Filter) ([]Chapter, Status, error)
+	ChapterFromBook(book BookRef, filter ...Filter) ([]Chapter, Status, error)
 
 	// Movies retrieves all the LOTR movies
 	//   filter - any number of Filter objects
 	Movies(filter ...Filter) ([]Movie, Status, error)
 
 	// QuoteFromMovie retrieves all the quotes from the provided movie
-	//   move - the movie from which to get the quotes
+	//   movie - the movie from which to get the quotes, as a MovieID or a *Movie
 	//   filter - any number of Filter objects
-	QuoteFromMovie(movie *Movie, filter ...Filter) ([]Quote, Status, error)
+	QuoteFromMovie(movie MovieRef, filter ...Filter) ([]Quote, Status, error)
 
 	// Characters retrieves all the characters from the LOTR
 	//   filter - any number of Filter objects
 	Characters(filter ...Filter) ([]Character, Status, error)
 
 	// QuoteFromCharacter retrieves all the quotes from a character
-	//   character - the character who spoke the quote
+	//   character - the character who spoke the quote, as a CharacterID or a *Character
 	//   filter - any number of Filter objects
-	QuoteFromCharacter(character *Character, filter ...Filter) ([]Quote, Status, error)
+	QuoteFromCharacter(character CharacterRef, filter ...Filter) ([]Quote, Status, error)
 
 	// Quotes retrieves all the LOTR quotes
 	//   filter - any number of Filter objects
@@ -47,118 +61,393 @@ type Client interface {
 	// Chapters retrieves all the LOTR chapters
 	//   filter - any number of Filter objects
 	Chapters(filter ...Filter) ([]Chapter, Status, error)
+
+	// QuotesForCharacters retrieves the quotes of every provided character concurrently
+	// the results are in the same order as characters, and a failure for one character
+	// does not affect the others
+	//   ctx - cancels any requests that have not completed
+	//   characters - the characters who spoke the quotes
+	//   opts - how many requests to run at once, and how fast
+	QuotesForCharacters(ctx context.Context, characters []Character, opts FanOutOptions) []QuotesResult
+
+	// QuotesForMovies retrieves the quotes of every provided movie concurrently
+	// the results are in the same order as movies, and a failure for one movie
+	// does not affect the others
+	//   ctx - cancels any requests that have not completed
+	//   movies - the movies from which to get the quotes
+	//   opts - how many requests to run at once, and how fast
+	QuotesForMovies(ctx context.Context, movies []Movie, opts FanOutOptions) []QuotesResult
+
+	// The Count methods return how many records the matching method above would find, without
+	// downloading them; only a single record is requested and Status.Total is returned.
+	// Limit, Page and Offset filters are ignored.
+
+	// CountBooks counts the LOTR books
+	//   filter - any number of Filter objects
+	CountBooks(filter ...Filter) (int, error)
+
+	// CountChapterFromBook counts the chapters of the provided book
+	//   book - the book from which to count the chapters, as a BookID or a *Book
+	//   filter - any number of Filter objects
+	CountChapterFromBook(book BookRef, filter ...Filter) (int, error)
+
+	// CountMovies counts the LOTR movies
+	//   filter - any number of Filter objects
+	CountMovies(filter ...Filter) (int, error)
+
+	// CountQuoteFromMovie counts the quotes from the provided movie
+	//   movie - the movie from which to count the quotes, as a MovieID or a *Movie
+	//   filter - any number of Filter objects
+	CountQuoteFromMovie(movie MovieRef, filter ...Filter) (int, error)
+
+	// CountCharacters counts the characters from the LOTR
+	//   filter - any number of Filter objects
+	CountCharacters(filter ...Filter) (int, error)
+
+	// CountQuoteFromCharacter counts the quotes from a character
+	//   character - the character who spoke the quotes, as a CharacterID or a *Character
+	//   filter - any number of Filter objects
+	CountQuoteFromCharacter(character CharacterRef, filter ...Filter) (int, error)
+
+	// CountQuotes counts the LOTR quotes
+	//   filter - any number of Filter objects
+	CountQuotes(filter ...Filter) (int, error)
+
+	// CountChapters counts the LOTR chapters
+	//   filter - any number of Filter objects
+	CountChapters(filter ...Filter) (int, error)
+
+	// CountChaptersForBooks counts the chapters of every provided book concurrently
+	// the results are in the same order as books
+	//   ctx - cancels any requests that have not completed
+	//   books - the books from which to count the chapters
+	//   opts - how many requests to run at once, and how fast
+	CountChaptersForBooks(ctx context.Context, books []Book, opts FanOutOptions) []CountResult
+
+	// CountQuotesForMovies counts the quotes of every provided movie concurrently
+	// the results are in the same order as movies
+	//   ctx - cancels any requests that have not completed
+	//   movies - the movies from which to count the quotes
+	//   opts - how many requests to run at once, and how fast
+	CountQuotesForMovies(ctx context.Context, movies []Movie, opts FanOutOptions) []CountResult
+
+	// CountQuotesForCharacters counts the quotes of every provided character concurrently
+	// the results are in the same order as characters
+	//   ctx - cancels any requests that have not completed
+	//   characters - the characters who spoke the quotes
+	//   opts - how many requests to run at once, and how fast
+	CountQuotesForCharacters(ctx context.Context, characters []Character, opts FanOutOptions) []CountResult
+
+	// FindCharacter returns the 10 characters whose names best match query, best first, allowing for
+	// partial names and typos (see CharacterIndex.Find); the characters are fetched once, on first use,
+	// into an index kept by the Client, and concurrent callers share a single fetch
+	//   ctx - stops waiting for the characters, if they have not been fetched yet
+	//   query - the name, or part of it, as typed
+	FindCharacter(ctx context.Context, query string) ([]CharacterMatch, error)
+
+	// CharacterIndex returns the index FindCharacter searches, fetching every character if it has not
+	// been built yet; use its Find method for a different number of matches
+	//   ctx - stops waiting for the characters, if they have not been fetched yet
+	CharacterIndex(ctx context.Context) (*CharacterIndex, error)
+
+	// RefreshCharacterIndex fetches every character again and replaces the index FindCharacter searches
+	// the previous index is kept, and searched, until the fetch succeeds
+	//   ctx - stops waiting for the characters
+	RefreshCharacterIndex(ctx context.Context) error
 }
 
 // client is a Client implementation
+// all fields are set once in NewClient and never modified afterwards (characterIndex guards
+// its own contents), which is what makes it safe to share between goroutines
 type client struct {
-	token  string
-	apiURL string
+	token       string
+	apiURL      string
+	httpClient  *http.Client
+	middlewares []Middleware
+	tracer      Tracer
+
+	// strictDecoding and onDrift check responses against the models (see drift.go)
+	strictDecoding bool
+	onDrift        func(DriftReport)
+
+	// characterIndex is built by the first fuzzy character lookup (see find.go)
+	characterIndex *characterIndexCache
+
+	// roundTrip sends the request through every middleware and then httpClient
+	roundTrip RoundTripFunc
+}
+
+// ClientOption configures optional behavior of the Client returned by NewClient
+type ClientOption func(*client)
+
+// WithHTTPClient makes the Client send its requests through the provided *http.Client
+// instead of the default one. The provided client should itself be shared; creating a
+// new one per Client throws away pooled connections.
+//   httpClient - the client used to send every request
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithBaseURL overrides the-one-api URL (https://the-one-api.dev/v2); mostly useful
+// for pointing the Client at a test server
+//   baseURL - the URL every endpoint is appended to
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *client) {
+		c.apiURL = baseURL
+	}
 }
 
 // NewClient creates a new Client
 // authToken - the-one-api authentication token
-func NewClient(authToken string) Client {
-	return client{
-		token:  authToken,
-		apiURL: apiURL,
+// opts - any number of ClientOption objects
+func NewClient(authToken string, opts ...ClientOption) Client {
+	c := &client{
+		token:          authToken,
+		apiURL:         apiURL,
+		httpClient:     newHTTPClient(),
+		tracer:         noopTracer{},
+		characterIndex: &characterIndexCache{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	// the attempt spans are always innermost so each retry gets its own span
+	c.roundTrip = chainMiddlewares(append(c.middlewares[:len(c.middlewares):len(c.middlewares)], c.traceAttempt), c.httpClient.Do)
+	return c
+}
+
+// newHTTPClient creates the *http.Client used when no WithHTTPClient option is provided
+// its transport is a copy of http.DefaultTransport tuned to keep more idle connections around
+func newHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = defaultMaxIdleConns
+	transport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	transport.IdleConnTimeout = defaultIdleConnTimeout
+	return &http.Client{
+		Transport: transport,
 	}
 }
 
+// allPages calls a Client method for every page of its records, pageSize at a time, and returns them all
+// the pages are traced as children of a single span named after the method (ie, lotrsdk.AllCharacters)
+//   T - the type of record requested
+//   ctx - cancels the requests
+//   tracer - the Tracer of the Client
+//   method - the name of the Client method, used to name the span
+//   pageSize - the limit of each request
+//   fetch - the Client method, such as Client.Characters
+func allPages[T any](ctx context.Context, tracer Tracer, method string, pageSize int, fetch func(filter ...Filter) ([]T, Status, error)) ([]T, error) {
+	ctx, span := tracer.Start(ctx, "lotrsdk.All"+method)
+	defer span.End()
+	span.SetAttributes(Attribute{Key: "lotrsdk.pagination.limit", Value: pageSize})
+
+	records := make([]T, 0)
+	for page := 1; ; page++ {
+		batch, status, err := fetch(WithContext(ctx), Limit(pageSize), Page(page))
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		records = append(records, batch...)
+		if page >= status.Pages || len(batch) == 0 {
+			span.SetAttributes(
+				Attribute{Key: "lotrsdk.pagination.pages", Value: page},
+				Attribute{Key: "lotrsdk.status.total", Value: status.Total},
+			)
+			return records, nil
+		}
+	}
+}
+
+// get performs the request for a Client method and decodes the response
+// the whole call (including decoding) is traced as a single span
+//   T - the type of record requested
+//   c - the client making the request
+//   method - the name of the Client method, used to name the span
+//   endpoint - the path requested
+//   filter - the filters passed to the method
+func get[T any](c *client, method, endpoint string, filter ...Filter) ([]T, Status, error) {
+	cfg := newCallConfig(filter...)
+	ctx, span := c.tracer.Start(cfg.ctx, "lotrsdk."+method)
+	defer span.End()
+	span.SetAttributes(
+		Attribute{Key: "lotrsdk.resource", Value: resourceFromEndpoint(endpoint)},
+		Attribute{Key: "lotrsdk.endpoint", Value: endpoint},
+	)
+	if rawQuery, err := MergeFilters(filter...).GenerateRawQuery(); err == nil {
+		span.SetAttributes(Attribute{Key: "lotrsdk.query", Value: RedactQuery(rawQuery)})
+	}
+
+	// a WithContext added last overrides any earlier one, so the request becomes a child of the span
+	filters := make([]Filter, 0, len(filter)+1)
+	filters = append(filters, filter...)
+	filters = append(filters, WithContext(ctx))
+
+	b, err := c.doRequest(endpoint, filters...)
+	if err != nil {
+		span.RecordError(err)
+		return nil, Status{}, err
+	}
+
+	docs, status, err := checkDrift[T](c, endpoint, b)
+	if err != nil {
+		span.RecordError(err)
+		return nil, Status{}, err
+	}
+	span.SetAttributes(
+		Attribute{Key: "lotrsdk.status.total", Value: status.Total},
+		Attribute{Key: "lotrsdk.status.page", Value: status.Page},
+		Attribute{Key: "lotrsdk.status.pages", Value: status.Pages},
+	)
+	return docs, status, nil
+}
+
 // helper function to perform the request
 // returns a byte array of the response JSON
-func (c client) doRequest(endpoint string, filter ...Filter) ([]byte, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s%s", c.apiURL, endpoint), nil)
+func (c *client) doRequest(endpoint string, filter ...Filter) ([]byte, error) {
+	cfg := newCallConfig(filter...)
+	merged := MergeFilters(filter...)
+	state := &requestState{
+		info: RequestInfo{
+			Resource: resourceFromEndpoint(endpoint),
+			Endpoint: endpoint,
+			Filter:   merged,
+		},
+		priority: priorityFromContext(cfg.ctx),
+	}
+	if cfg.priority != nil {
+		state.priority = *cfg.priority
+	}
+	ctx := withRequestState(cfg.ctx, state)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s%s", c.apiURL, endpoint), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request")
 	}
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
 
-	rawQuery, err := MergeFilters(filter...).GenerateRawQuery()
+	rawQuery, err := merged.GenerateRawQuery()
 	if err != nil {
 		return nil, err
 	}
 	req.URL.RawQuery = rawQuery
 
-	client := http.Client{}
-	resp, err := client.Do(req)
+	start := time.Now()
+	resp, err := c.roundTrip(req)
+	if cfg.meta != nil {
+		fillResponseMeta(cfg.meta, resp, state, time.Since(start))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("request %s failed: %w", req.URL, err)
-	} else if resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("request %s failed with status code %d:%s", req.URL, resp.StatusCode, resp.Status)
 	}
 	defer resp.Body.Close()
 
-	return io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		// only the start of the body is needed to explain the error
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLength))
+		message, _ := envelopeMessage(b)
+		return nil, &StatusError{
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Message:    message,
+			Snippet:    snippet(b),
+		}
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response of %s: %w", req.URL, err)
+	}
+	// servers that do not set a content type often get text/plain, so the body has the last word
+	if contentType := resp.Header.Get("Content-Type"); !isJSONContentType(contentType) && !json.Valid(b) {
+		return nil, &DecodeError{ContentType: contentType, Snippet: snippet(b), Err: ErrNotJSON}
+	}
+	return b, nil
 }
 
-func (c client) Books(filter ...Filter) ([]Book, Status, error) {
-	b, err := c.doRequest("/book", filter...)
+func (c *client) Books(filter ...Filter) ([]Book, Status, error) {
+	books, status, err := get[Book](c, "Books", "/book", filter...)
 	if err != nil {
 		return nil, Status{}, fmt.Errorf("request for books failed: %w", err)
 	}
 
-	return unmarshalJSON[Book](b)
+	return books, status, nil
 }
 
-func (c client) ChapterFromBook(book *Book, filter ...Filter) ([]Chapter, Status, error) {
-	b, err := c.doRequest(fmt.Sprintf("/book/%s/chapter", book.ID), filter...)
+func (c *client) ChapterFromBook(book BookRef, filter ...Filter) ([]Chapter, Status, error) {
+	id, err := resolveBook(book)
+	if err != nil {
+		return nil, Status{}, fmt.Errorf("request for chapters failed: %w", err)
+	}
+	chapters, status, err := get[Chapter](c, "ChapterFromBook", fmt.Sprintf("/book/%s/chapter", id), filter...)
 	if err != nil {
 		return nil, Status{}, fmt.Errorf("request for chapters failed: %w", err)
 	}
 
-	return unmarshalJSON[Chapter](b)
+	return chapters, status, nil
 }
 
-func (c client) Movies(filter ...Filter) ([]Movie, Status, error) {
-	b, err := c.doRequest("/movie", filter...)
+func (c *client) Movies(filter ...Filter) ([]Movie, Status, error) {
+	movies, status, err := get[Movie](c, "Movies", "/movie", filter...)
 	if err != nil {
 		return nil, Status{}, fmt.Errorf("request for movies failed: %w", err)
 	}
 
-	return unmarshalJSON[Movie](b)
+	return movies, status, nil
 }
 
-func (c client) QuoteFromMovie(movie *Movie, filter ...Filter) ([]Quote, Status, error) {
-	b, err := c.doRequest(fmt.Sprintf("/movie/%s/quote", movie.ID), filter...)
+func (c *client) QuoteFromMovie(movie MovieRef, filter ...Filter) ([]Quote, Status, error) {
+	id, err := resolveMovie(movie)
+	if err != nil {
+		return nil, Status{}, fmt.Errorf("request for quotes failed: %w", err)
+	}
+	quotes, status, err := get[Quote](c, "QuoteFromMovie", fmt.Sprintf("/movie/%s/quote", id), filter...)
 	if err != nil {
 		return nil, Status{}, fmt.Errorf("request for quotes failed: %w", err)
 	}
 
-	return unmarshalJSON[Quote](b)
+	return quotes, status, nil
 }
 
-func (c client) Characters(filter ...Filter) ([]Character, Status, error) {
-	b, err := c.doRequest("/character", filter...)
+func (c *client) Characters(filter ...Filter) ([]Character, Status, error) {
+	characters, status, err := get[Character](c, "Characters", "/character", filter...)
 	if err != nil {
 		return nil, Status{}, fmt.Errorf("request for characters failed: %w", err)
 	}
 
-	return unmarshalJSON[Character](b)
+	return characters, status, nil
 }
 
-func (c client) QuoteFromCharacter(character *Character, filter ...Filter) ([]Quote, Status, error) {
-	b, err := c.doRequest(fmt.Sprintf("/character/%s/quote", character.ID), filter...)
+func (c *client) QuoteFromCharacter(character CharacterRef, filter ...Filter) ([]Quote, Status, error) {
+	id, err := resolveCharacter(character)
+	if err != nil {
+		return nil, Status{}, fmt.Errorf("request for quotes failed: %w", err)
+	}
+	quotes, status, err := get[Quote](c, "QuoteFromCharacter", fmt.Sprintf("/character/%s/quote", id), filter...)
 	if err != nil {
 		return nil, Status{}, fmt.Errorf("request for quotes failed: %w", err)
 	}
 
-	return unmarshalJSON[Quote](b)
+	return quotes, status, nil
 }
 
-func (c client) Quotes(filter ...Filter) ([]Quote, Status, error) {
-	b, err := c.doRequest("/quote", filter...)
+func (c *client) Quotes(filter ...Filter) ([]Quote, Status, error) {
+	quotes, status, err := get[Quote](c, "Quotes", "/quote", filter...)
 	if err != nil {
 		return nil, Status{}, fmt.Errorf("request for quotes failed: %w", err)
 	}
 
-	return unmarshalJSON[Quote](b)
+	return quotes, status, nil
 }
 
-func (c client) Chapters(filter ...Filter) ([]Chapter, Status, error) {
-	b, err := c.doRequest("/chapter", filter...)
+func (c *client) Chapters(filter ...Filter) ([]Chapter, Status, error) {
+	chapters, status, err := get[Chapter](c, "Chapters", "/chapter", filter...)
 	if err != nil {
 		return nil, Status{}, fmt.Errorf("request for chapters failed: %w", err)
 	}
 
-	return unmarshalJSON[Chapter](b)
+	return chapters, status, nil
 }
@@ -1,10 +1,14 @@
 package lotrsdk
 
 import (
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,10 +25,7 @@ func newTestOneRingClient() (Client, *[]*http.Request) {
 		requests = append(requests, r)
 	}))
 
-	client := client{
-		token:  "fake-token",
-		apiURL: ts.URL,
-	}
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
 
 	return client, &requests
 }
@@ -49,14 +50,14 @@ func TestBook(t *testing.T) {
 func TestBookChapter(t *testing.T) {
 	client, requests := newTestOneRingClient()
 	book := Book{
-		ID:   "47",
+		ID:   "5cf58077b53e011a64671583",
 		Name: "The Two Towers",
 	}
 
 	client.ChapterFromBook(&book)
 
 	assert.Equal(t, len(*requests), 1)
-	assert.Equal(t, (*requests)[0].URL.Path, "/book/47/chapter")
+	assert.Equal(t, (*requests)[0].URL.Path, "/book/5cf58077b53e011a64671583/chapter")
 	assert.Equal(t, len((*requests)[0].URL.Query()), 0)
 }
 
@@ -72,15 +73,15 @@ func TestMovie(t *testing.T) {
 func TestMovieQuote(t *testing.T) {
 	client, requests := newTestOneRingClient()
 	movie := Movie{
-		ID:               "501",
+		ID:               "5cd95395de30eff6ebccde5b",
 		Name:             "The Two Towers",
-		AcademyAwardWins: 12,
+		AcademyAwardWins: Some(12),
 	}
 
 	client.QuoteFromMovie(&movie)
 
 	assert.Equal(t, len(*requests), 1)
-	assert.Equal(t, (*requests)[0].URL.Path, "/movie/501/quote")
+	assert.Equal(t, (*requests)[0].URL.Path, "/movie/5cd95395de30eff6ebccde5b/quote")
 	assert.Equal(t, len((*requests)[0].URL.Query()), 0)
 }
 
@@ -96,7 +97,7 @@ func TestCharacter(t *testing.T) {
 func TestCharacterQuote(t *testing.T) {
 	client, requests := newTestOneRingClient()
 	character := Character{
-		ID:   "21F3C",
+		ID:   "5cd99d4bde30eff6ebccfe9e",
 		Name: "Tom Bombadil",
 		Hair: "Brown",
 	}
@@ -104,7 +105,7 @@ func TestCharacterQuote(t *testing.T) {
 	client.QuoteFromCharacter(&character)
 
 	assert.Equal(t, len(*requests), 1)
-	assert.Equal(t, (*requests)[0].URL.Path, "/character/21F3C/quote")
+	assert.Equal(t, (*requests)[0].URL.Path, "/character/5cd99d4bde30eff6ebccfe9e/quote")
 	assert.Equal(t, len((*requests)[0].URL.Query()), 0)
 }
 
@@ -240,10 +241,7 @@ func newTestClientWithMockServer(data string) Client {
 		w.Write([]byte(data))
 	}))
 
-	client := client{
-		token:  "fake-token",
-		apiURL: ts.URL,
-	}
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
 
 	return client
 }
@@ -255,11 +253,11 @@ func TestUnmarshalBook(t *testing.T) {
 
 	assert.Nil(t, err)
 	assert.Equal(t, len(books), 3)
-	assert.Equal(t, books[0].ID, "5cf5805fb53e011a64671582")
+	assert.Equal(t, books[0].ID, BookID("5cf5805fb53e011a64671582"))
 	assert.Equal(t, books[0].Name, "The Fellowship Of The Ring")
-	assert.Equal(t, books[1].ID, "5cf58077b53e011a64671583")
+	assert.Equal(t, books[1].ID, BookID("5cf58077b53e011a64671583"))
 	assert.Equal(t, books[1].Name, "The Two Towers")
-	assert.Equal(t, books[2].ID, "5cf58080b53e011a64671584")
+	assert.Equal(t, books[2].ID, BookID("5cf58080b53e011a64671584"))
 	assert.Equal(t, books[2].Name, "The Return Of The King")
 
 	assert.Equal(t, status.Total, 3)
@@ -276,8 +274,8 @@ func TestUnmarshalMovie(t *testing.T) {
 
 	assert.Nil(t, err)
 	assert.Equal(t, len(movies), 1)
-	assert.Equal(t, movies[0].ID, "5cd95395de30eff6ebccde56")
-	assert.Equal(t, movies[0].RuntimeInMinutes, 558)
+	assert.Equal(t, movies[0].ID, MovieID("5cd95395de30eff6ebccde56"))
+	assert.Equal(t, movies[0].RuntimeInMinutes, Some(558))
 }
 
 func TestUnmarshalCharacter(t *testing.T) {
@@ -287,7 +285,7 @@ func TestUnmarshalCharacter(t *testing.T) {
 
 	assert.Nil(t, err)
 	assert.Equal(t, len(characters), 1)
-	assert.Equal(t, characters[0].ID, "5cd99d4bde30eff6ebccfbbe")
+	assert.Equal(t, characters[0].ID, CharacterID("5cd99d4bde30eff6ebccfbbe"))
 	assert.Equal(t, characters[0].Race, "Human")
 }
 
@@ -298,8 +296,8 @@ func TestUnmarshalQuote(t *testing.T) {
 
 	assert.Nil(t, err)
 	assert.Equal(t, len(quotes), 1)
-	assert.Equal(t, quotes[0].ID, "5cd96e05de30eff6ebcce7e9")
-	assert.Equal(t, quotes[0].Character, "5cd99d4bde30eff6ebccfe9e")
+	assert.Equal(t, quotes[0].ID, QuoteID("5cd96e05de30eff6ebcce7e9"))
+	assert.Equal(t, quotes[0].Character, CharacterID("5cd99d4bde30eff6ebccfe9e"))
 }
 
 func TestUnmarshalChapter(t *testing.T) {
@@ -310,6 +308,114 @@ func TestUnmarshalChapter(t *testing.T) {
 
 	assert.Nil(t, err)
 	assert.Equal(t, len(chapters), 1)
-	assert.Equal(t, chapters[0].ID, "6091b6d6d58360f988133b8b")
-	assert.Equal(t, chapters[0].Book, "5cf5805fb53e011a64671582")
+	assert.Equal(t, chapters[0].ID, ChapterID("6091b6d6d58360f988133b8b"))
+	assert.Equal(t, chapters[0].Book, BookID("5cf5805fb53e011a64671582"))
+}
+
+const bookData = `{"docs":[{"_id":"5cf5805fb53e011a64671582","name":"The Fellowship Of The Ring"}],"total":1,"limit":1000,"offset":0,"page":1,"pages":1}`
+
+func TestConcurrentRequests(t *testing.T) {
+	var received int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&received, 1)
+		w.Write([]byte(bookData))
+	}))
+	defer ts.Close()
+
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+	const goroutines = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			books, _, err := client.Books()
+			if err != nil {
+				errs <- err
+				return
+			}
+			if len(books) != 1 {
+				errs <- fmt.Errorf("expected 1 book; got %d", len(books))
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, int64(goroutines), atomic.LoadInt64(&received))
+}
+
+func TestSharedTransportReusesConnections(t *testing.T) {
+	var conns int64
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(bookData))
+	}))
+	ts.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&conns, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+	for i := 0; i < 10; i++ {
+		_, _, err := client.Books()
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&conns))
+}
+
+// newBenchmarkServer starts a TLS server so the benchmarks include the handshake cost
+// that connection reuse avoids
+func newBenchmarkServer() *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(bookData))
+	}))
+}
+
+// BenchmarkSharedTransport issues parallel requests through one Client
+func BenchmarkSharedTransport(b *testing.B) {
+	ts := newBenchmarkServer()
+	defer ts.Close()
+
+	transport := ts.Client().Transport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithHTTPClient(&http.Client{Transport: transport}))
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, _, err := client.Books(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkTransportPerRequest mimics creating a new transport for every request,
+// which forces a new connection and TLS handshake each time
+func BenchmarkTransportPerRequest(b *testing.B) {
+	ts := newBenchmarkServer()
+	defer ts.Close()
+
+	base := ts.Client().Transport.(*http.Transport)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			transport := base.Clone()
+			client := NewClient("fake-token", WithBaseURL(ts.URL), WithHTTPClient(&http.Client{Transport: transport}))
+			if _, _, err := client.Books(); err != nil {
+				b.Fatal(err)
+			}
+			transport.CloseIdleConnections()
+		}
+	})
 }
@@ -0,0 +1,145 @@
+package lotrsdk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithRequestCoalescing adds CoalescingMiddleware() to the Client's request chain
+// it should be added before any other middleware that sends requests (ie, RetryMiddleware),
+// so that identical calls share the retries as well
+func WithRequestCoalescing() ClientOption {
+	return WithMiddleware(CoalescingMiddleware())
+}
+
+// CoalescingMiddleware makes identical requests that are in flight at the same time share a single
+// round trip. Requests are identical if they have the same endpoint, the same filters (in any order)
+// and the same token. Every caller gets its own copy of the response, so the decoded slices are
+// never shared.
+//
+// The shared round trip is not tied to any one caller: a caller whose context is done stops
+// waiting and gets the context's error, while the others keep waiting. The round trip is only
+// cancelled once every caller waiting for it has given up.
+func CoalescingMiddleware() Middleware {
+	co := &coalescer{
+		flights: map[string]*flight{},
+	}
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return co.roundTrip(next, req)
+		}
+	}
+}
+
+type coalescer struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a single round trip shared by every identical request made while it is in progress
+type flight struct {
+	// done is closed once the fields below are set
+	done   chan struct{}
+	cancel context.CancelFunc
+	// waiters is the number of callers still waiting; guarded by coalescer.mu
+	waiters int
+
+	state *requestState
+	resp  *http.Response
+	body  []byte
+	err   error
+}
+
+func (co *coalescer) roundTrip(next RoundTripFunc, req *http.Request) (*http.Response, error) {
+	key := fmt.Sprintf("%s %s?%s %s", req.Method, req.URL.Path, canonicalQuery(req.URL.RawQuery), req.Header.Get("Authorization"))
+	callerState := getRequestState(req.Context())
+
+	co.mu.Lock()
+	f, ok := co.flights[key]
+	if !ok {
+		f = co.start(next, req, key, callerState)
+	}
+	f.waiters++
+	co.mu.Unlock()
+
+	select {
+	case <-f.done:
+	case <-req.Context().Done():
+		co.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 && co.flights[key] == f {
+			// nobody is waiting for the round trip anymore
+			delete(co.flights, key)
+			f.cancel()
+		}
+		co.mu.Unlock()
+		return nil, req.Context().Err()
+	}
+
+	if callerState != nil && f.state != nil {
+		callerState.retries = f.state.retries
+		callerState.cacheLookup = f.state.cacheLookup
+		callerState.fromCache = f.state.fromCache
+		callerState.stale = f.state.stale
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := *f.resp
+	resp.Header = f.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(f.body))
+	resp.Request = req
+	return &resp, nil
+}
+
+// start sends the request for a new flight in the background; co.mu must be held
+func (co *coalescer) start(next RoundTripFunc, req *http.Request, key string, callerState *requestState) *flight {
+	ctx, cancel := context.WithCancel(detachedContext{parent: req.Context()})
+	f := &flight{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	if callerState != nil {
+		// the flight gets its own state, so middlewares after this one do not write to the first caller's
+		state := *callerState
+		f.state = &state
+		ctx = withRequestState(ctx, f.state)
+	}
+	co.flights[key] = f
+
+	flightReq := req.Clone(ctx)
+	go func() {
+		defer cancel()
+		resp, err := next(flightReq)
+		if err == nil {
+			f.body, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+			f.resp = resp
+		}
+		f.err = err
+
+		co.mu.Lock()
+		if co.flights[key] == f {
+			delete(co.flights, key)
+		}
+		co.mu.Unlock()
+		close(f.done)
+	}()
+	return f
+}
+
+// detachedContext keeps the values of its parent but is never done, so work started for one
+// caller is not cancelled along with that caller's context
+type detachedContext struct {
+	parent context.Context
+}
+
+func (dc detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (dc detachedContext) Done() <-chan struct{}       { return nil }
+func (dc detachedContext) Err() error                  { return nil }
+func (dc detachedContext) Value(key any) any           { return dc.parent.Value(key) }
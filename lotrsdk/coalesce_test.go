@@ -0,0 +1,147 @@
+package lotrsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSlowServer counts the requests it gets and answers each once release is closed
+func newSlowServer(hits *int64, release chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(hits, 1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte(bookData))
+	}))
+}
+
+// waitForHits waits until the server got n requests
+func waitForHits(hits *int64, n int64) {
+	for atomic.LoadInt64(hits) < n {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCoalescing(t *testing.T) {
+	var hits int64
+	release := make(chan struct{})
+	ts := newSlowServer(&hits, release)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithRequestCoalescing())
+
+	const callers = 10
+	results := make([][]Book, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// the same filters in a different order are the same request
+			filters := []Filter{Limit(5), Sort("name", SortOrderAscending)}
+			if i%2 == 1 {
+				filters[0], filters[1] = filters[1], filters[0]
+			}
+			books, _, err := client.Books(filters...)
+			assert.Nil(t, err)
+			results[i] = books
+		}()
+	}
+	waitForHits(&hits, 1)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, atomic.LoadInt64(&hits), int64(1))
+	results[0][0].Name = "changed"
+	for _, books := range results[1:] {
+		assert.Equal(t, books[0].Name, "The Fellowship Of The Ring")
+	}
+
+	// once the flight is done, the next call goes to the network again
+	client.Books(Limit(5), Sort("name", SortOrderAscending))
+	assert.Equal(t, atomic.LoadInt64(&hits), int64(2))
+}
+
+func TestCoalescingDifferentRequests(t *testing.T) {
+	var hits int64
+	release := make(chan struct{})
+	close(release)
+	ts := newSlowServer(&hits, release)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithRequestCoalescing())
+
+	client.Books(Limit(1))
+	client.Books(Limit(2))
+	client.Movies(Limit(1))
+
+	assert.Equal(t, atomic.LoadInt64(&hits), int64(3))
+}
+
+func TestCoalescingCancelledCaller(t *testing.T) {
+	var hits int64
+	release := make(chan struct{})
+	ts := newSlowServer(&hits, release)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithRequestCoalescing())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelledErr := make(chan error)
+	go func() {
+		_, _, err := client.Books(WithContext(ctx))
+		cancelledErr <- err
+	}()
+	waitForHits(&hits, 1)
+
+	otherErr := make(chan error)
+	go func() {
+		books, _, err := client.Books()
+		if err == nil && len(books) != 1 {
+			err = fmt.Errorf("expected 1 book; got %d", len(books))
+		}
+		otherErr <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// the first caller gives up, which must not fail the second
+	cancel()
+	assert.ErrorIs(t, <-cancelledErr, context.Canceled)
+	close(release)
+	assert.Nil(t, <-otherErr)
+	assert.Equal(t, atomic.LoadInt64(&hits), int64(1))
+}
+
+func TestCoalescingAllCallersCancelled(t *testing.T) {
+	var hits int64
+	release := make(chan struct{})
+	ts := newSlowServer(&hits, release)
+	defer ts.Close()
+	defer close(release)
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithRequestCoalescing())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, err := client.Books(WithContext(ctx))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the abandoned flight was dropped, so a new call starts a new round trip
+	go client.Books()
+	waitForHits(&hits, 2)
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	assert.Equal(t, canonicalQuery(""), "")
+	assert.Equal(t, canonicalQuery("limit=5&name=Frodo&!hair"), "!hair&limit=5&name=Frodo")
+	assert.Equal(t, canonicalQuery("name=Frodo&limit=5&!hair"), "!hair&limit=5&name=Frodo")
+}
@@ -0,0 +1,159 @@
+package lotrsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// CountResult is the outcome of counting the records for a single input of a batch count method
+type CountResult struct {
+	Count int
+	Err   error
+}
+
+// count requests a single record matching the filters and returns Status.Total
+// any pagination filters are dropped, since they would change what the API reports as the total
+func (c *client) count(method, endpoint string, filter ...Filter) (int, error) {
+	filters := append(withoutPagination(filter), Limit(1))
+	_, status, err := get[json.RawMessage](c, method, endpoint, filters...)
+	if err != nil {
+		return 0, err
+	}
+	return status.Total, nil
+}
+
+// withoutPagination returns a copy of filters with the Limit, Page and Offset filters removed
+// (including those nested via MergeFilters)
+func withoutPagination(filters []Filter) []Filter {
+	result := make([]Filter, 0, len(filters))
+	for _, f := range filters {
+		switch ft := f.(type) {
+		case paginationFilter:
+			continue
+		case Filters:
+			result = append(result, Filters(withoutPagination(ft)))
+		default:
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+func (c *client) CountBooks(filter ...Filter) (int, error) {
+	n, err := c.count("CountBooks", "/book", filter...)
+	if err != nil {
+		return 0, fmt.Errorf("request for book count failed: %w", err)
+	}
+	return n, nil
+}
+
+func (c *client) CountChapterFromBook(book BookRef, filter ...Filter) (int, error) {
+	id, err := resolveBook(book)
+	if err != nil {
+		return 0, fmt.Errorf("request for chapter count failed: %w", err)
+	}
+	n, err := c.count("CountChapterFromBook", fmt.Sprintf("/book/%s/chapter", id), filter...)
+	if err != nil {
+		return 0, fmt.Errorf("request for chapter count failed: %w", err)
+	}
+	return n, nil
+}
+
+func (c *client) CountMovies(filter ...Filter) (int, error) {
+	n, err := c.count("CountMovies", "/movie", filter...)
+	if err != nil {
+		return 0, fmt.Errorf("request for movie count failed: %w", err)
+	}
+	return n, nil
+}
+
+func (c *client) CountQuoteFromMovie(movie MovieRef, filter ...Filter) (int, error) {
+	id, err := resolveMovie(movie)
+	if err != nil {
+		return 0, fmt.Errorf("request for quote count failed: %w", err)
+	}
+	n, err := c.count("CountQuoteFromMovie", fmt.Sprintf("/movie/%s/quote", id), filter...)
+	if err != nil {
+		return 0, fmt.Errorf("request for quote count failed: %w", err)
+	}
+	return n, nil
+}
+
+func (c *client) CountCharacters(filter ...Filter) (int, error) {
+	n, err := c.count("CountCharacters", "/character", filter...)
+	if err != nil {
+		return 0, fmt.Errorf("request for character count failed: %w", err)
+	}
+	return n, nil
+}
+
+func (c *client) CountQuoteFromCharacter(character CharacterRef, filter ...Filter) (int, error) {
+	id, err := resolveCharacter(character)
+	if err != nil {
+		return 0, fmt.Errorf("request for quote count failed: %w", err)
+	}
+	n, err := c.count("CountQuoteFromCharacter", fmt.Sprintf("/character/%s/quote", id), filter...)
+	if err != nil {
+		return 0, fmt.Errorf("request for quote count failed: %w", err)
+	}
+	return n, nil
+}
+
+func (c *client) CountQuotes(filter ...Filter) (int, error) {
+	n, err := c.count("CountQuotes", "/quote", filter...)
+	if err != nil {
+		return 0, fmt.Errorf("request for quote count failed: %w", err)
+	}
+	return n, nil
+}
+
+func (c *client) CountChapters(filter ...Filter) (int, error) {
+	n, err := c.count("CountChapters", "/chapter", filter...)
+	if err != nil {
+		return 0, fmt.Errorf("request for chapter count failed: %w", err)
+	}
+	return n, nil
+}
+
+func (c *client) CountChaptersForBooks(ctx context.Context, books []Book, opts FanOutOptions) []CountResult {
+	ctx, span := c.tracer.Start(ctx, "lotrsdk.CountChaptersForBooks")
+	defer span.End()
+	span.SetAttributes(Attribute{Key: "lotrsdk.fanout.inputs", Value: len(books)})
+
+	filters := fanOutFilters(ctx, opts)
+	return fanOut(ctx, len(books), opts, func(i int) CountResult {
+		n, err := c.CountChapterFromBook(&books[i], filters...)
+		return CountResult{Count: n, Err: err}
+	}, func(err error) CountResult {
+		return CountResult{Err: err}
+	})
+}
+
+func (c *client) CountQuotesForMovies(ctx context.Context, movies []Movie, opts FanOutOptions) []CountResult {
+	ctx, span := c.tracer.Start(ctx, "lotrsdk.CountQuotesForMovies")
+	defer span.End()
+	span.SetAttributes(Attribute{Key: "lotrsdk.fanout.inputs", Value: len(movies)})
+
+	filters := fanOutFilters(ctx, opts)
+	return fanOut(ctx, len(movies), opts, func(i int) CountResult {
+		n, err := c.CountQuoteFromMovie(&movies[i], filters...)
+		return CountResult{Count: n, Err: err}
+	}, func(err error) CountResult {
+		return CountResult{Err: err}
+	})
+}
+
+func (c *client) CountQuotesForCharacters(ctx context.Context, characters []Character, opts FanOutOptions) []CountResult {
+	ctx, span := c.tracer.Start(ctx, "lotrsdk.CountQuotesForCharacters")
+	defer span.End()
+	span.SetAttributes(Attribute{Key: "lotrsdk.fanout.inputs", Value: len(characters)})
+
+	filters := fanOutFilters(ctx, opts)
+	return fanOut(ctx, len(characters), opts, func(i int) CountResult {
+		n, err := c.CountQuoteFromCharacter(&characters[i], filters...)
+		return CountResult{Count: n, Err: err}
+	}, func(err error) CountResult {
+		return CountResult{Err: err}
+	})
+}
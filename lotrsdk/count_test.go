@@ -0,0 +1,73 @@
+package lotrsdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCount(t *testing.T) {
+	data := `{"docs":[{"_id":"5cd96e05de30eff6ebcce7e9","dialog":"Deagol!","movie":"5cd95395de30eff6ebccde5d","character":"5cd99d4bde30eff6ebccfe9e"}],"total":216,"limit":1,"offset":0,"page":1,"pages":216}`
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Write([]byte(data))
+	}))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+	character := Character{ID: "5cd99d4bde30eff6ebccfd0d", Name: "Gandalf"}
+	count, err := client.CountQuoteFromCharacter(&character, MergeFilters(Limit(5), Page(2)), BinaryFilter("dialog", FilterCompareEqual, "/ring/i"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, count, 216)
+	assert.Equal(t, len(queries), 1)
+	assert.Equal(t, queries[0], "dialog=%2Fring%2Fi&limit=1")
+}
+
+func TestCountEndpoints(t *testing.T) {
+	client, requests := newTestOneRingClient()
+	client.CountBooks()
+	client.CountChapterFromBook(&Book{ID: "5cf58077b53e011a64671583"})
+	client.CountMovies()
+	client.CountQuoteFromMovie(&Movie{ID: "5cd95395de30eff6ebccde5b"})
+	client.CountCharacters()
+	client.CountQuoteFromCharacter(&Character{ID: "5cd99d4bde30eff6ebccfe9e"})
+	client.CountQuotes()
+	client.CountChapters()
+
+	paths := []string{"/book", "/book/5cf58077b53e011a64671583/chapter", "/movie", "/movie/5cd95395de30eff6ebccde5b/quote", "/character", "/character/5cd99d4bde30eff6ebccfe9e/quote", "/quote", "/chapter"}
+	assert.Equal(t, len(*requests), len(paths))
+	for i, path := range paths {
+		assert.Equal(t, (*requests)[i].URL.Path, path)
+		assert.Equal(t, (*requests)[i].URL.RawQuery, "limit=1")
+	}
+}
+
+func TestCountQuotesForCharacters(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch strings.Split(r.URL.Path, "/")[2] {
+		case "5cd99d4bde30eff6ebccfc15":
+			w.Write([]byte(`{"docs":[{}],"total":109,"limit":1,"offset":0,"page":1,"pages":109}`))
+		case "5cd99d4bde30eff6ebccfd0d":
+			w.Write([]byte(`{"docs":[{}],"total":91,"limit":1,"offset":0,"page":1,"pages":91}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+	results := client.CountQuotesForCharacters(context.Background(), []Character{{ID: "5cd99d4bde30eff6ebccfc15"}, {ID: "000000000000000000000000"}, {ID: "5cd99d4bde30eff6ebccfd0d"}}, FanOutOptions{})
+
+	assert.Equal(t, len(results), 3)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, results[0].Count, 109)
+	assert.NotNil(t, results[1].Err)
+	assert.Nil(t, results[2].Err)
+	assert.Equal(t, results[2].Count, 91)
+}
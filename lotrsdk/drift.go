@@ -0,0 +1,266 @@
+package lotrsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrSchemaDrift is wrapped by a DecodeError when strict decoding finds the response does not match the models
+var ErrSchemaDrift = errors.New("response does not match the expected schema")
+
+// knownExtraFields are fields the-one-api sends that the models deliberately leave out, by resource
+// (quotes carry both "_id" and a duplicate "id")
+var knownExtraFields = map[string][]string{
+	"quote": {"id"},
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// DriftReport describes how a response differs from the models it is decoded into
+// field paths look like "total" or "docs[].chapterName"
+type DriftReport struct {
+	Resource string
+	Endpoint string
+	// UnknownFields are fields in the response that no model field corresponds to
+	UnknownFields []string
+	// MissingFields are model fields absent from at least one record
+	MissingFields []string
+	// TypeMismatches are fields whose JSON type can not be decoded into their model field
+	TypeMismatches []TypeMismatch
+}
+
+// TypeMismatch is a field whose JSON type does not match its model field
+type TypeMismatch struct {
+	Field string
+	// Expected is the Go type of the model field
+	Expected string
+	// Actual is the JSON type found (string, number, bool, object, array or null)
+	Actual string
+}
+
+// Empty reports whether the response matched the models exactly
+func (dr DriftReport) Empty() bool {
+	return len(dr.UnknownFields) == 0 && len(dr.MissingFields) == 0 && len(dr.TypeMismatches) == 0
+}
+
+func (dr DriftReport) String() string {
+	parts := make([]string, 0, 3)
+	if len(dr.UnknownFields) > 0 {
+		parts = append(parts, "unknown fields "+strings.Join(dr.UnknownFields, ", "))
+	}
+	if len(dr.MissingFields) > 0 {
+		parts = append(parts, "missing fields "+strings.Join(dr.MissingFields, ", "))
+	}
+	for _, mismatch := range dr.TypeMismatches {
+		parts = append(parts, fmt.Sprintf("%s is %s instead of %s", mismatch.Field, mismatch.Actual, mismatch.Expected))
+	}
+	return fmt.Sprintf("%s (%s): %s", dr.Resource, dr.Endpoint, strings.Join(parts, "; "))
+}
+
+// WithStrictDecoding makes every call fail with an error wrapping ErrSchemaDrift when the response has fields the
+// models do not know about (like json.Decoder.DisallowUnknownFields), lacks any model or Status field, or has a field
+// of the wrong type; the DecodeError's Drift holds the details. Meant for catching API changes in CI.
+func WithStrictDecoding() ClientOption {
+	return func(c *client) {
+		c.strictDecoding = true
+	}
+}
+
+// WithDriftReporter makes the Client check every response against the models like WithStrictDecoding, but instead
+// of failing, calls report with what differs; calls succeed or fail just as they would without it.
+// Meant for alerting in production.
+//   report - called (from the goroutine making the call) for every response that differs from the models
+func WithDriftReporter(report func(DriftReport)) ClientOption {
+	return func(c *client) {
+		c.onDrift = report
+	}
+}
+
+// checkDrift decodes b like get[T] does, then checks the response against the models when strict decoding
+// or drift reporting is on
+//   T - the type of record requested
+//   c - the client making the request
+//   endpoint - the path requested
+//   b - the response body
+func checkDrift[T any](c *client, endpoint string, b []byte) ([]T, Status, error) {
+	docs, status, err := unmarshalJSON[T](b)
+	if !c.strictDecoding && c.onDrift == nil {
+		return docs, status, err
+	}
+	// responses that are not records at all are reported by unmarshalJSON on their own
+	if err != nil && !errors.Is(err, ErrInvalidJSON) {
+		return docs, status, err
+	}
+
+	report, ok := driftReport[T](resourceFromEndpoint(endpoint), endpoint, b)
+	if !ok || report.Empty() {
+		return docs, status, err
+	}
+	if c.onDrift != nil {
+		c.onDrift(report)
+	}
+	if c.strictDecoding {
+		return nil, Status{}, &DecodeError{Snippet: snippet(b), Drift: &report, Err: fmt.Errorf("%w: %s", ErrSchemaDrift, report)}
+	}
+	return docs, status, err
+}
+
+// driftReport compares the response b against unmarshalStruct and T
+// the second return is false if b is not a JSON object
+func driftReport[T any](resource, endpoint string, b []byte) (DriftReport, bool) {
+	top := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &top); err != nil {
+		return DriftReport{}, false
+	}
+
+	checker := newDriftChecker()
+	checker.object("", top, reflect.TypeOf(Status{}), nil, "docs")
+
+	var docs []json.RawMessage
+	docsType := reflect.TypeOf((*T)(nil)).Elem()
+	if raw, ok := top["docs"]; !ok {
+		checker.missing["docs"] = true
+	} else if err := json.Unmarshal(raw, &docs); err != nil {
+		checker.mismatch("docs", "[]"+docsType.String(), raw)
+	} else if docsType.Kind() == reflect.Struct {
+		for _, doc := range docs {
+			fields := map[string]json.RawMessage{}
+			if err := json.Unmarshal(doc, &fields); err != nil {
+				checker.mismatch("docs[]", docsType.String(), doc)
+				continue
+			}
+			checker.object("docs[].", fields, docsType, knownExtraFields[resource])
+		}
+	}
+	return checker.report(resource, endpoint), true
+}
+
+// driftChecker accumulates the differences found across every record of a response
+type driftChecker struct {
+	unknown    map[string]bool
+	missing    map[string]bool
+	mismatches map[string]TypeMismatch
+}
+
+func newDriftChecker() *driftChecker {
+	return &driftChecker{
+		unknown:    make(map[string]bool),
+		missing:    make(map[string]bool),
+		mismatches: make(map[string]TypeMismatch),
+	}
+}
+
+// object checks the fields of a JSON object against the struct type t
+//   prefix - the path of the object, prepended to its fields' names
+//   fields - the fields of the JSON object
+//   t - the struct type the object decodes into
+//   extra - fields that are known but have no struct field
+//   handled - fields that are checked separately
+func (dc *driftChecker) object(prefix string, fields map[string]json.RawMessage, t reflect.Type, extra []string, handled ...string) {
+	known := make(map[string]bool)
+	for _, name := range extra {
+		known[name] = true
+	}
+	for _, name := range handled {
+		known[name] = true
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		known[name] = true
+
+		raw, ok := fields[name]
+		if !ok {
+			dc.missing[prefix+name] = true
+			continue
+		}
+		if !jsonKindFits(raw, field.Type) {
+			dc.mismatch(prefix+name, field.Type.String(), raw)
+		}
+	}
+
+	for name := range fields {
+		if !known[name] {
+			dc.unknown[prefix+name] = true
+		}
+	}
+}
+
+func (dc *driftChecker) mismatch(field, expected string, raw json.RawMessage) {
+	dc.mismatches[field] = TypeMismatch{Field: field, Expected: expected, Actual: jsonKind(raw)}
+}
+
+func (dc *driftChecker) report(resource, endpoint string) DriftReport {
+	report := DriftReport{
+		Resource:      resource,
+		Endpoint:      endpoint,
+		UnknownFields: sortedKeys(dc.unknown),
+		MissingFields: sortedKeys(dc.missing),
+	}
+	for _, field := range sortedKeys(dc.mismatches) {
+		report.TypeMismatches = append(report.TypeMismatches, dc.mismatches[field])
+	}
+	return report
+}
+
+// jsonKind returns the JSON type of raw: string, number, bool, object, array or null
+func jsonKind(raw json.RawMessage) string {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" {
+		return "null"
+	}
+	switch trimmed[0] {
+	case '"':
+		return "string"
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case 't', 'f':
+		return "bool"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
+
+// jsonKindFits reports whether raw can be decoded into a value of type t
+// types with their own UnmarshalJSON are trusted to handle whatever they are given
+func jsonKindFits(raw json.RawMessage, t reflect.Type) bool {
+	if t.Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return true
+	}
+	kind := jsonKind(raw)
+	if kind == "null" {
+		return t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return jsonKindFits(raw, t.Elem())
+	case reflect.Interface:
+		return true
+	case reflect.String:
+		return kind == "string"
+	case reflect.Bool:
+		return kind == "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return kind == "number"
+	case reflect.Slice, reflect.Array:
+		return kind == "array"
+	case reflect.Struct, reflect.Map:
+		return kind == "object"
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+package lotrsdk
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const quoteData = `{"docs":[{"_id":"5cd96e05de30eff6ebcce7e9","dialog":"Deagol!","movie":"5cd95395de30eff6ebccde5d","character":"5cd99d4bde30eff6ebccfe9e","id":"5cd96e05de30eff6ebcce7e9"}],"total":1,"limit":1000,"offset":0,"page":1,"pages":1}`
+
+func newDataServer(data string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(data))
+	}))
+}
+
+func TestDriftReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected DriftReport
+	}{
+		{
+			name:     "matches",
+			data:     bookData,
+			expected: DriftReport{},
+		},
+		{
+			name: "renamed field",
+			data: `{"docs":[{"_id":"1","name":"a"},{"_id":"2","title":"b"}],"total":2,"limit":1000,"offset":0,"page":1,"pages":1}`,
+			expected: DriftReport{
+				UnknownFields: []string{"docs[].title"},
+				MissingFields: []string{"docs[].name"},
+			},
+		},
+		{
+			name: "status fields",
+			data: `{"docs":[],"total":"0","limit":1000,"page":1,"pages":1,"next":2}`,
+			expected: DriftReport{
+				UnknownFields:  []string{"next"},
+				MissingFields:  []string{"offset"},
+				TypeMismatches: []TypeMismatch{{Field: "total", Expected: "int", Actual: "string"}},
+			},
+		},
+		{
+			name: "wrong types",
+			data: `{"docs":[{"_id":5,"name":null}],"total":1,"limit":1000,"offset":0,"page":1,"pages":1}`,
+			expected: DriftReport{
+				TypeMismatches: []TypeMismatch{
+					{Field: "docs[]._id", Expected: "lotrsdk.BookID", Actual: "number"},
+					{Field: "docs[].name", Expected: "string", Actual: "null"},
+				},
+			},
+		},
+		{
+			name: "missing docs",
+			data: `{"total":0}`,
+			expected: DriftReport{
+				MissingFields: []string{"docs", "limit", "offset", "page", "pages"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, ok := driftReport[Book]("book", "/book", []byte(test.data))
+			assert.True(t, ok)
+			assert.Equal(t, report.Empty(), test.expected.Empty())
+			assert.ElementsMatch(t, report.UnknownFields, test.expected.UnknownFields)
+			assert.ElementsMatch(t, report.MissingFields, test.expected.MissingFields)
+			assert.ElementsMatch(t, report.TypeMismatches, test.expected.TypeMismatches)
+		})
+	}
+}
+
+func TestDriftReportKnownExtraFields(t *testing.T) {
+	report, ok := driftReport[Quote]("quote", "/quote", []byte(quoteData))
+	assert.True(t, ok)
+	assert.True(t, report.Empty(), report.String())
+}
+
+func TestStrictDecoding(t *testing.T) {
+	ts := newDataServer(`{"docs":[{"_id":"1","chapter":"A Long-expected Party","book":"2"}],"total":1,"limit":1000,"offset":0,"page":1,"pages":1}`)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithStrictDecoding())
+
+	chapters, _, err := client.Chapters()
+	assert.Nil(t, chapters)
+	assert.ErrorIs(t, err, ErrSchemaDrift)
+	var decodeErr *DecodeError
+	if assert.True(t, errors.As(err, &decodeErr)) {
+		assert.Equal(t, decodeErr.Drift.Resource, "chapter")
+		assert.Equal(t, decodeErr.Drift.UnknownFields, []string{"docs[].chapter"})
+		assert.Equal(t, decodeErr.Drift.MissingFields, []string{"docs[].chapterName"})
+	}
+
+	ok := newDataServer(quoteData)
+	defer ok.Close()
+	client = NewClient("fake-token", WithBaseURL(ok.URL), WithStrictDecoding())
+	quotes, _, err := client.Quotes()
+	assert.Nil(t, err)
+	assert.Equal(t, len(quotes), 1)
+	count, err := client.CountQuotes()
+	assert.Nil(t, err)
+	assert.Equal(t, count, 1)
+}
+
+func TestDriftReporter(t *testing.T) {
+	ts := newDataServer(`{"docs":[{"_id":"1","name":"The Hobbit","author":"Tolkien"}],"total":1,"limit":1000,"offset":0,"page":1,"pages":1}`)
+	defer ts.Close()
+	var reports []DriftReport
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithDriftReporter(func(report DriftReport) {
+		reports = append(reports, report)
+	}))
+
+	// lenient mode reports the drift but the call still succeeds
+	books, _, err := client.Books()
+	assert.Nil(t, err)
+	assert.Equal(t, books[0].Name, "The Hobbit")
+	if assert.Equal(t, len(reports), 1) {
+		assert.Equal(t, reports[0].Endpoint, "/book")
+		assert.Equal(t, reports[0].UnknownFields, []string{"docs[].author"})
+	}
+
+	// type mismatches are reported along with the decoding error
+	mismatched := newDataServer(`{"docs":[{"_id":"1","name":7}],"total":1,"limit":1000,"offset":0,"page":1,"pages":1}`)
+	defer mismatched.Close()
+	client = NewClient("fake-token", WithBaseURL(mismatched.URL), WithDriftReporter(func(report DriftReport) {
+		reports = append(reports, report)
+	}))
+	_, _, err = client.Books()
+	assert.ErrorIs(t, err, ErrInvalidJSON)
+	if assert.Equal(t, len(reports), 2) {
+		assert.Equal(t, reports[1].TypeMismatches, []TypeMismatch{{Field: "docs[].name", Expected: "string", Actual: "number"}})
+	}
+}
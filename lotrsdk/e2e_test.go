@@ -0,0 +1,79 @@
+//go:build e2e
+
+package lotrsdk
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newE2EClient returns a Client backed by the cassette testdata/cassettes/<name>.json
+// with LOTR_RECORD=1 (and LOTR_API_TOKEN set) the requests go to the-one-api and are recorded;
+// otherwise they are replayed from the cassette, with no network access or token needed.
+// Until a cassette has been recorded, the synthetic fixture testdata/fixtures/<name>.json is
+// replayed instead; it is hand-written in the API's format, so it does not check the SDK
+// against the API itself
+func newE2EClient(t *testing.T, name string) Client {
+	path := filepath.Join("testdata", "cassettes", name+".json")
+	mode := CassetteReplay
+	token := "replayed-token"
+	if os.Getenv("LOTR_RECORD") == "1" {
+		mode = CassetteRecord
+		token = os.Getenv("LOTR_API_TOKEN")
+		if token == "" {
+			t.Fatal("LOTR_API_TOKEN must be set to record cassettes")
+		}
+		os.Remove(path)
+	} else if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		path = filepath.Join("testdata", "fixtures", name+".json")
+	}
+
+	cassette, err := NewCassette(path, mode, nil)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("no cassette %s; record it with LOTR_RECORD=1 LOTR_API_TOKEN=<token> go test -tags e2e ./...", path)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(token, WithHTTPClient(&http.Client{Transport: cassette}))
+}
+
+func TestE2EBooks(t *testing.T) {
+	client := newE2EClient(t, "books")
+
+	books, status, err := client.Books()
+	assert.Nil(t, err)
+	assert.Equal(t, status.Total, 3)
+	assert.Equal(t, len(books), 3)
+
+	chapters, _, err := client.ChapterFromBook(&books[0])
+	assert.Nil(t, err)
+	assert.NotEmpty(t, chapters)
+}
+
+func TestE2EMovies(t *testing.T) {
+	client := newE2EClient(t, "movies")
+
+	movies, _, err := client.Movies(BinaryFilter("name", FilterCompareEqual, "The Return of the King"))
+	assert.Nil(t, err)
+	assert.Equal(t, len(movies), 1)
+	assert.Equal(t, movies[0].AcademyAwardWins, Some(11))
+}
+
+func TestE2ECharacters(t *testing.T) {
+	client := newE2EClient(t, "characters")
+
+	characters, _, err := client.Characters(BinaryFilter("name", FilterCompareEqual, "Frodo Baggins"))
+	assert.Nil(t, err)
+	assert.Equal(t, len(characters), 1)
+	assert.Equal(t, characters[0].Race, "Hobbit")
+
+	count, err := client.CountQuotes(BinaryFilter("character", FilterCompareEqual, string(characters[0].ID)))
+	assert.Nil(t, err)
+	assert.Greater(t, count, 0)
+}
@@ -0,0 +1,281 @@
+package lotrsdk
+
+import (
+	"strings"
+)
+
+// this file contains normalized types for Character's Race, Gender and Realm, whose raw values are
+// spelled inconsistently ("Elf"/"Elves", "Male"/"male")
+
+// Race is a normalized Character race
+// values outside the constants below are kept as their raw spelling (see IsOther)
+type Race string
+
+const (
+	RaceUnknown   Race = ""
+	RaceHuman     Race = "Human"
+	RaceHobbit    Race = "Hobbit"
+	RaceElf       Race = "Elf"
+	RaceHalfElven Race = "Half-elven"
+	RaceDwarf     Race = "Dwarf"
+	RaceAinur     Race = "Ainur"
+	RaceMaiar     Race = "Maiar"
+	RaceBalrog    Race = "Balrog"
+	RaceOrc       Race = "Orc"
+	RaceUrukHai   Race = "Uruk-hai"
+	RaceTroll     Race = "Troll"
+	RaceEnt       Race = "Ent"
+	RaceEagle     Race = "Great Eagle"
+	RaceDragon    Race = "Dragon"
+)
+
+// Gender is a normalized Character gender
+// values outside the constants below are kept as their raw spelling (see IsOther)
+type Gender string
+
+const (
+	GenderUnknown Gender = ""
+	GenderMale    Gender = "Male"
+	GenderFemale  Gender = "Female"
+)
+
+// Realm is a normalized Character realm
+// values outside the constants below are kept as their raw spelling (see IsOther)
+type Realm string
+
+const (
+	RealmUnknown         Realm = ""
+	RealmGondor          Realm = "Gondor"
+	RealmArnor           Realm = "Arnor"
+	RealmReunitedKingdom Realm = "Reunited Kingdom"
+	RealmRohan           Realm = "Rohan"
+	RealmShire           Realm = "The Shire"
+	RealmRivendell       Realm = "Rivendell"
+	RealmLothlorien      Realm = "Lothlórien"
+	RealmWoodlandRealm   Realm = "Woodland Realm"
+	RealmLindon          Realm = "Lindon"
+	RealmErebor          Realm = "Erebor"
+	RealmKhazadDum       Realm = "Khazad-dûm"
+	RealmNumenor         Realm = "Númenor"
+	RealmDoriath         Realm = "Doriath"
+	RealmGondolin        Realm = "Gondolin"
+	RealmNargothrond     Realm = "Nargothrond"
+	RealmMordor          Realm = "Mordor"
+	RealmIsengard        Realm = "Isengard"
+	RealmValinor         Realm = "Valinor"
+)
+
+// the raw spellings of each value found in the-one-api's data; the first is the canonical one
+var (
+	raceTable = newEnumTable(map[Race][]string{
+		RaceHuman:     {"Human", "Humans", "Men", "Man"},
+		RaceHobbit:    {"Hobbit", "Hobbits"},
+		RaceElf:       {"Elf", "Elves"},
+		RaceHalfElven: {"Half-elven", "Half-Elven", "Half-elf", "Half-Elf"},
+		RaceDwarf:     {"Dwarf", "Dwarves"},
+		RaceAinur:     {"Ainur", "Ainu", "Valar", "Vala"},
+		RaceMaiar:     {"Maiar", "Maia"},
+		RaceBalrog:    {"Balrog", "Balrogs"},
+		RaceOrc:       {"Orc", "Orcs", "Goblin", "Goblins"},
+		RaceUrukHai:   {"Uruk-hai", "Uruk-Hai", "Black Uruk", "Uruk"},
+		RaceTroll:     {"Troll", "Trolls", "Stone-troll", "Stone-trolls"},
+		RaceEnt:       {"Ent", "Ents"},
+		RaceEagle:     {"Great Eagle", "Great Eagles", "Eagle", "Eagles"},
+		RaceDragon:    {"Dragon", "Dragons"},
+	})
+	genderTable = newEnumTable(map[Gender][]string{
+		GenderMale:   {"Male", "male", "Males"},
+		GenderFemale: {"Female", "female", "Females"},
+	})
+	realmTable = newEnumTable(map[Realm][]string{
+		RealmGondor:          {"Gondor"},
+		RealmArnor:           {"Arnor"},
+		RealmReunitedKingdom: {"Reunited Kingdom"},
+		RealmRohan:           {"Rohan"},
+		RealmShire:           {"The Shire", "Shire"},
+		RealmRivendell:       {"Rivendell", "Imladris"},
+		RealmLothlorien:      {"Lothlórien", "Lothlorien", "Lórien", "Lorien"},
+		RealmWoodlandRealm:   {"Woodland Realm", "Mirkwood"},
+		RealmLindon:          {"Lindon"},
+		RealmErebor:          {"Erebor", "Lonely Mountain"},
+		RealmKhazadDum:       {"Khazad-dûm", "Khazad-dum", "Moria"},
+		RealmNumenor:         {"Númenor", "Numenor"},
+		RealmDoriath:         {"Doriath"},
+		RealmGondolin:        {"Gondolin"},
+		RealmNargothrond:     {"Nargothrond"},
+		RealmMordor:          {"Mordor"},
+		RealmIsengard:        {"Isengard"},
+		RealmValinor:         {"Valinor"},
+	})
+)
+
+// ParseRace returns the Race for a raw race, whatever its spelling or casing ("Elves" is RaceElf)
+// unknown values ("", "NaN") are RaceUnknown, and unrecognized ones are kept as they are
+//   raw - the raw race, such as Character.Race
+func ParseRace(raw string) Race {
+	return raceTable.parse(raw)
+}
+
+// ParseGender returns the Gender for a raw gender, whatever its casing ("male" is GenderMale)
+// unknown values ("", "NaN") are GenderUnknown, and unrecognized ones are kept as they are
+//   raw - the raw gender, such as Character.Gender
+func ParseGender(raw string) Gender {
+	return genderTable.parse(raw)
+}
+
+// ParseRealm returns the Realm for a raw realm, whatever its spelling ("Lothlorien" is RealmLothlorien)
+// unknown values ("", "NaN") are RealmUnknown, and unrecognized ones are kept as they are
+//   raw - the raw realm, such as Character.Realm
+func ParseRealm(raw string) Realm {
+	return realmTable.parse(raw)
+}
+
+// ParsedRace returns Race normalized (see ParseRace)
+func (c Character) ParsedRace() Race {
+	return ParseRace(c.Race)
+}
+
+// ParsedGender returns Gender normalized (see ParseGender)
+func (c Character) ParsedGender() Gender {
+	return ParseGender(c.Gender)
+}
+
+// ParsedRealm returns Realm normalized (see ParseRealm)
+func (c Character) ParsedRealm() Realm {
+	return ParseRealm(c.Realm)
+}
+
+// IsOther reports whether the race is known but not one of the Race constants
+func (r Race) IsOther() bool {
+	return r != RaceUnknown && !raceTable.known(r)
+}
+
+// IsOther reports whether the gender is known but not one of the Gender constants
+func (g Gender) IsOther() bool {
+	return g != GenderUnknown && !genderTable.known(g)
+}
+
+// IsOther reports whether the realm is known but not one of the Realm constants
+func (r Realm) IsOther() bool {
+	return r != RealmUnknown && !realmTable.known(r)
+}
+
+func (r Race) String() string {
+	return enumString(r)
+}
+
+func (g Gender) String() string {
+	return enumString(g)
+}
+
+func (r Realm) String() string {
+	return enumString(r)
+}
+
+// RaceIn only selects the characters of one of the races, in any of their raw spellings:
+// RaceIn(RaceElf, RaceMaiar) is the same as BinaryFilter("race", FilterCompareEqual, "Elf", "Elves", "Maiar", "Maia")
+//   races - the races to select
+func RaceIn(races ...Race) Filter {
+	return enumFilter("race", FilterCompareEqual, raceTable.expand(races))
+}
+
+// RaceNotIn only selects the characters of none of the races, in any of their raw spellings
+//   races - the races to leave out
+func RaceNotIn(races ...Race) Filter {
+	return enumFilter("race", FilterCompareNotEqual, raceTable.expand(races))
+}
+
+// GenderIn only selects the characters of one of the genders, in any of their raw spellings
+//   genders - the genders to select
+func GenderIn(genders ...Gender) Filter {
+	return enumFilter("gender", FilterCompareEqual, genderTable.expand(genders))
+}
+
+// GenderNotIn only selects the characters of none of the genders, in any of their raw spellings
+//   genders - the genders to leave out
+func GenderNotIn(genders ...Gender) Filter {
+	return enumFilter("gender", FilterCompareNotEqual, genderTable.expand(genders))
+}
+
+// RealmIn only selects the characters of one of the realms, in any of their raw spellings
+//   realms - the realms to select
+func RealmIn(realms ...Realm) Filter {
+	return enumFilter("realm", FilterCompareEqual, realmTable.expand(realms))
+}
+
+// RealmNotIn only selects the characters of none of the realms, in any of their raw spellings
+//   realms - the realms to leave out
+func RealmNotIn(realms ...Realm) Filter {
+	return enumFilter("realm", FilterCompareNotEqual, realmTable.expand(realms))
+}
+
+// enumFilter is a binaryFilter on every spelling; without any, generating its query fails
+func enumFilter(key string, operator FilterCompareType, spellings []string) Filter {
+	return binaryFilter{
+		key:      key,
+		operator: operator,
+		values:   spellings,
+	}
+}
+
+func enumString[E ~string](value E) string {
+	if value == "" {
+		return "Unknown"
+	}
+	return string(value)
+}
+
+// enumTable maps the raw spellings of an enum to its values and back
+type enumTable[E ~string] struct {
+	// values is keyed by normalized spelling
+	values    map[string]E
+	spellings map[E][]string
+}
+
+func newEnumTable[E ~string](spellings map[E][]string) enumTable[E] {
+	table := enumTable[E]{
+		values:    make(map[string]E),
+		spellings: spellings,
+	}
+	for value, raws := range spellings {
+		for _, raw := range raws {
+			table.values[normalizeSpelling(raw)] = value
+		}
+	}
+	return table
+}
+
+func (et enumTable[E]) parse(raw string) E {
+	if IsUnknown(raw) {
+		return ""
+	}
+	if value, ok := et.values[normalizeSpelling(raw)]; ok {
+		return value
+	}
+	return E(strings.TrimSpace(raw))
+}
+
+func (et enumTable[E]) known(value E) bool {
+	_, ok := et.spellings[value]
+	return ok
+}
+
+// expand returns every raw spelling of the values; unrecognized values are their own spelling,
+// and unknown values have none
+func (et enumTable[E]) expand(values []E) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if spellings, ok := et.spellings[value]; ok {
+			result = append(result, spellings...)
+		} else if value != "" {
+			result = append(result, string(value))
+		}
+	}
+	return result
+}
+
+// normalizeSpelling lower cases s and makes spaces, hyphens and underscores equivalent
+func normalizeSpelling(s string) string {
+	s = strings.ToLower(strings.NewReplacer("-", " ", "_", " ").Replace(s))
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,98 @@
+package lotrsdk
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRace(t *testing.T) {
+	tests := map[string]Race{
+		"Elf":          RaceElf,
+		"Elves":        RaceElf,
+		"elves":        RaceElf,
+		" ELF ":        RaceElf,
+		"Men":          RaceHuman,
+		"Half-elven":   RaceHalfElven,
+		"half elven":   RaceHalfElven,
+		"Maiar":        RaceMaiar,
+		"Uruk-hai":     RaceUrukHai,
+		"Great Eagles": RaceEagle,
+		"":             RaceUnknown,
+		"NaN":          RaceUnknown,
+		"Werewolves":   Race("Werewolves"),
+		" Raven ":      Race("Raven"),
+	}
+	for raw, expected := range tests {
+		assert.Equal(t, ParseRace(raw), expected, raw)
+	}
+
+	assert.False(t, RaceElf.IsOther())
+	assert.False(t, RaceUnknown.IsOther())
+	assert.True(t, ParseRace("Werewolves").IsOther())
+	assert.Equal(t, RaceUnknown.String(), "Unknown")
+	assert.Equal(t, RaceEagle.String(), "Great Eagle")
+}
+
+func TestParseGenderAndRealm(t *testing.T) {
+	assert.Equal(t, ParseGender("Male"), GenderMale)
+	assert.Equal(t, ParseGender("male"), GenderMale)
+	assert.Equal(t, ParseGender("Female"), GenderFemale)
+	assert.Equal(t, ParseGender("NaN"), GenderUnknown)
+	assert.True(t, ParseGender("Non-binary").IsOther())
+
+	assert.Equal(t, ParseRealm("Lothlorien"), RealmLothlorien)
+	assert.Equal(t, ParseRealm("Lothlórien"), RealmLothlorien)
+	assert.Equal(t, ParseRealm("khazad-dum"), RealmKhazadDum)
+	assert.Equal(t, ParseRealm("Shire"), RealmShire)
+	assert.Equal(t, ParseRealm(""), RealmUnknown)
+	assert.Equal(t, ParseRealm("Dorwinion"), Realm("Dorwinion"))
+}
+
+func TestCharacterEnums(t *testing.T) {
+	characters := []Character{
+		{Name: "Legolas", Race: "Elf", Gender: "Male", Realm: "Woodland Realm"},
+		{Name: "Arwen", Race: "Elves", Gender: "female", Realm: ""},
+		{Name: "Gandalf", Race: "Maiar", Gender: "Male", Realm: "NaN"},
+		{Name: "Carcharoth", Race: "Werewolves", Gender: "Male", Realm: ""},
+	}
+
+	facets := make(map[Race]int)
+	for _, character := range characters {
+		facets[character.ParsedRace()]++
+	}
+	assert.Equal(t, facets, map[Race]int{RaceElf: 2, RaceMaiar: 1, Race("Werewolves"): 1})
+	assert.Equal(t, characters[1].ParsedGender(), GenderFemale)
+	assert.Equal(t, characters[0].ParsedRealm(), RealmWoodlandRealm)
+	assert.Equal(t, characters[2].ParsedRealm(), RealmUnknown)
+}
+
+func TestEnumFilters(t *testing.T) {
+	query, err := RaceIn(RaceElf, RaceMaiar).GenerateRawQuery()
+	assert.Nil(t, err)
+	assert.Equal(t, query, "race=Elf,Elves,Maiar,Maia")
+
+	query, err = RaceNotIn(RaceHobbit, Race("Werewolves"), RaceUnknown).GenerateRawQuery()
+	assert.Nil(t, err)
+	assert.Equal(t, query, "race!=Hobbit,Hobbits,Werewolves")
+
+	query, err = GenderIn(GenderMale).GenerateRawQuery()
+	assert.Nil(t, err)
+	assert.Equal(t, query, "gender=Male,male,Males")
+
+	query, err = RealmIn(RealmLothlorien).GenerateRawQuery()
+	assert.Nil(t, err)
+	unescaped, _ := url.QueryUnescape(query)
+	assert.Equal(t, unescaped, "realm=Lothlórien,Lothlorien,Lórien,Lorien")
+
+	_, err = RaceIn().GenerateRawQuery()
+	assert.NotNil(t, err)
+	_, err = GenderNotIn(GenderUnknown).GenerateRawQuery()
+	assert.NotNil(t, err)
+
+	client, requests := newTestOneRingClient()
+	client.Characters(RaceIn(RaceElf), RealmNotIn(RealmMordor))
+	assertQueryContains(t, (*requests)[0], "race=Elf,Elves")
+	assertQueryContains(t, (*requests)[0], "realm!=Mordor")
+}
@@ -0,0 +1,147 @@
+package lotrsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxSnippetLength is how much of a response body is kept in errors
+	maxSnippetLength = 200
+	// maxErrorBodyLength is how much of the body of a failed response is read
+	maxErrorBodyLength = 4096
+)
+
+var (
+	// ErrNotJSON is wrapped by a DecodeError when the response is not JSON (for instance an HTML maintenance page)
+	ErrNotJSON = errors.New("response is not JSON")
+	// ErrInvalidJSON is wrapped by a DecodeError when the response is malformed or truncated JSON,
+	// or its documents do not have the expected types
+	ErrInvalidJSON = errors.New("response is invalid JSON")
+	// ErrAPIError is wrapped by a DecodeError when the response is an error envelope
+	// ({"success":false,"message":"..."}) despite its successful status code
+	ErrAPIError = errors.New("response is an API error")
+	// ErrMissingDocs is wrapped by a DecodeError when the response has no docs field
+	ErrMissingDocs = errors.New("response has no docs")
+)
+
+// DecodeError is returned (wrapped) when a successful response can not be turned into records
+// use errors.Is with ErrNotJSON, ErrInvalidJSON, ErrAPIError, ErrMissingDocs or ErrSchemaDrift to tell the cases apart
+type DecodeError struct {
+	// ContentType is the Content-Type header of the response, if known
+	ContentType string
+	// Message is the message of an error envelope
+	Message string
+	// Snippet is the start of the response body
+	Snippet string
+	// Drift is what differs from the models, when strict decoding failed the call
+	Drift *DriftReport
+	// Err is one of the sentinel errors above, possibly wrapping the error from encoding/json
+	Err error
+}
+
+func (de *DecodeError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("failed to decode response: ")
+	sb.WriteString(de.Err.Error())
+	if de.Message != "" {
+		fmt.Fprintf(&sb, ": %s", de.Message)
+	}
+	if de.ContentType != "" {
+		fmt.Fprintf(&sb, " (content type %s)", de.ContentType)
+	}
+	fmt.Fprintf(&sb, "; body: %q", de.Snippet)
+	return sb.String()
+}
+
+func (de *DecodeError) Unwrap() error {
+	return de.Err
+}
+
+// invalidJSONError is an ErrInvalidJSON caused by an error from encoding/json, which errors.As
+// can still reach (for instance a *json.SyntaxError)
+type invalidJSONError struct {
+	err error
+}
+
+func (ie *invalidJSONError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrInvalidJSON, ie.err)
+}
+
+func (ie *invalidJSONError) Is(target error) bool {
+	return target == ErrInvalidJSON
+}
+
+func (ie *invalidJSONError) Unwrap() error {
+	return ie.err
+}
+
+// StatusError is returned (wrapped) when the-one-api answers with a status code of 300 or more
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	// Message is the message of the error envelope in the body, if there was one
+	Message string
+	// Snippet is the start of the response body
+	Snippet string
+}
+
+func (se *StatusError) Error() string {
+	msg := fmt.Sprintf("request %s failed with status code %d:%s", se.URL, se.StatusCode, se.Status)
+	if se.Message != "" {
+		msg += ": " + se.Message
+	} else if se.Snippet != "" {
+		msg += fmt.Sprintf("; body: %q", se.Snippet)
+	}
+	return msg
+}
+
+// errorEnvelope is the shape of the-one-api's error responses
+type errorEnvelope struct {
+	Success *bool  `json:"success"`
+	Message string `json:"message"`
+}
+
+// envelopeMessage returns the message of the error envelope in b, if b is one
+func envelopeMessage(b []byte) (string, bool) {
+	envelope := errorEnvelope{}
+	if json.Unmarshal(b, &envelope) != nil {
+		return "", false
+	}
+	if (envelope.Success != nil && !*envelope.Success) || envelope.Message != "" {
+		return envelope.Message, true
+	}
+	return "", false
+}
+
+// isJSONContentType reports whether contentType is a JSON media type; an empty content type is
+// given the benefit of the doubt
+//   contentType - the Content-Type header of a response
+func isJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// snippet returns the start of b, cut at a rune boundary
+//   b - a response body
+func snippet(b []byte) string {
+	if len(b) <= maxSnippetLength {
+		return string(b)
+	}
+	end := maxSnippetLength
+	for end > 0 && !utf8.RuneStart(b[end]) {
+		end--
+	}
+	return string(b[:end]) + "..."
+}
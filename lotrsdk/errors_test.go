@@ -0,0 +1,124 @@
+package lotrsdk
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    error
+		message     string
+	}{
+		{"html page", "text/html; charset=utf-8", faultMaintenancePage, ErrNotJSON, ""},
+		{"html without content type", "", faultMaintenancePage, ErrNotJSON, ""},
+		{"empty body", "application/json", "", ErrNotJSON, ""},
+		{"error envelope", "application/json", `{"success":false,"message":"Unauthorized."}`, ErrAPIError, "Unauthorized."},
+		{"message only", "application/json", `{"message":"Something went wrong."}`, ErrAPIError, "Something went wrong."},
+		{"missing docs", "application/json", `{"total":0,"limit":1000}`, ErrMissingDocs, ""},
+		{"null docs", "application/json", `{"docs":null}`, ErrMissingDocs, ""},
+		{"truncated", "application/json", bookData[:len(bookData)/2], ErrInvalidJSON, ""},
+		{"wrong type", "application/json", `{"docs":[{"_id":5}]}`, ErrInvalidJSON, ""},
+		{"array", "application/json", `[]`, ErrInvalidJSON, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", test.contentType)
+				w.Write([]byte(test.body))
+			}))
+			defer ts.Close()
+			client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+			books, _, err := client.Books()
+			assert.Nil(t, books)
+			assert.ErrorIs(t, err, test.expected)
+
+			var decodeErr *DecodeError
+			if assert.True(t, errors.As(err, &decodeErr)) {
+				assert.Equal(t, decodeErr.Message, test.message)
+				assert.True(t, strings.HasPrefix(test.body, strings.TrimSuffix(decodeErr.Snippet, "...")))
+			}
+		})
+	}
+}
+
+func TestDecodeInvalidJSONKeepsCause(t *testing.T) {
+	_, _, err := unmarshalJSON[Book]([]byte(bookData[:len(bookData)/2]))
+	assert.ErrorIs(t, err, ErrInvalidJSON)
+	var syntaxErr *json.SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+
+	_, _, err = unmarshalJSON[Book]([]byte(`{"docs":[{"_id":5}]}`))
+	assert.ErrorIs(t, err, ErrInvalidJSON)
+	var typeErr *json.UnmarshalTypeError
+	assert.True(t, errors.As(err, &typeErr))
+}
+
+func TestDecodeEmptyDocs(t *testing.T) {
+	books, status, err := unmarshalJSON[Book]([]byte(`{"docs":[],"total":0}`))
+	assert.Nil(t, err)
+	assert.Equal(t, books, []Book{})
+	assert.Equal(t, status.Total, 0)
+}
+
+func TestSnippet(t *testing.T) {
+	assert.Equal(t, snippet([]byte("short")), "short")
+
+	long := strings.Repeat("a", maxSnippetLength-1) + "é" + "tail"
+	s := snippet([]byte(long))
+	assert.Equal(t, s, strings.Repeat("a", maxSnippetLength-1)+"...")
+}
+
+// closeTracker records whether the response body was closed
+type closeTracker struct {
+	io.ReadCloser
+	closed bool
+}
+
+func (ct *closeTracker) Close() error {
+	ct.closed = true
+	return ct.ReadCloser.Close()
+}
+
+func TestStatusErrorClosesBody(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"success":false,"message":"Unauthorized."}`))
+	}))
+	defer ts.Close()
+
+	var body *closeTracker
+	track := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if err == nil {
+				body = &closeTracker{ReadCloser: resp.Body}
+				resp.Body = body
+			}
+			return resp, err
+		}
+	}
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithMiddleware(track))
+
+	_, _, err := client.Books()
+	var statusErr *StatusError
+	if assert.True(t, errors.As(err, &statusErr)) {
+		assert.Equal(t, statusErr.StatusCode, http.StatusUnauthorized)
+		assert.Equal(t, statusErr.Message, "Unauthorized.")
+	}
+	assert.True(t, strings.Contains(err.Error(), "Unauthorized."))
+	assert.True(t, body.closed)
+}
@@ -0,0 +1,115 @@
+package lotrsdk
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFanOutWorkers = 4
+)
+
+// FanOutOptions controls how the fan-out methods (QuotesForCharacters, QuotesForMovies)
+// spread their requests over the API
+type FanOutOptions struct {
+	// Workers is the maximum number of requests in flight at once; defaults to 4
+	Workers int
+
+	// Requests and Interval cap how many requests are started in any window of length
+	// Interval (ie, 10 requests per second); no cap is applied if either is zero
+	Requests int
+	Interval time.Duration
+
+	// Filters are passed along to every request
+	Filters []Filter
+}
+
+// QuotesResult is the outcome of fetching the quotes for a single input of a fan-out method
+// exactly one of Quotes and Err is meaningful
+type QuotesResult struct {
+	Quotes []Quote
+	Status Status
+	Err    error
+}
+
+func (c *client) QuotesForCharacters(ctx context.Context, characters []Character, opts FanOutOptions) []QuotesResult {
+	ctx, span := c.tracer.Start(ctx, "lotrsdk.QuotesForCharacters")
+	defer span.End()
+	span.SetAttributes(Attribute{Key: "lotrsdk.fanout.inputs", Value: len(characters)})
+
+	filters := fanOutFilters(ctx, opts)
+	return fanOut(ctx, len(characters), opts, func(i int) QuotesResult {
+		quotes, status, err := c.QuoteFromCharacter(&characters[i], filters...)
+		return QuotesResult{Quotes: quotes, Status: status, Err: err}
+	}, func(err error) QuotesResult {
+		return QuotesResult{Err: err}
+	})
+}
+
+func (c *client) QuotesForMovies(ctx context.Context, movies []Movie, opts FanOutOptions) []QuotesResult {
+	ctx, span := c.tracer.Start(ctx, "lotrsdk.QuotesForMovies")
+	defer span.End()
+	span.SetAttributes(Attribute{Key: "lotrsdk.fanout.inputs", Value: len(movies)})
+
+	filters := fanOutFilters(ctx, opts)
+	return fanOut(ctx, len(movies), opts, func(i int) QuotesResult {
+		quotes, status, err := c.QuoteFromMovie(&movies[i], filters...)
+		return QuotesResult{Quotes: quotes, Status: status, Err: err}
+	}, func(err error) QuotesResult {
+		return QuotesResult{Err: err}
+	})
+}
+
+// fanOutFilters builds the filters shared (read-only) by every request of a fan-out
+func fanOutFilters(ctx context.Context, opts FanOutOptions) []Filter {
+	filters := make([]Filter, 0, len(opts.Filters)+1)
+	filters = append(filters, opts.Filters...)
+	return append(filters, WithContext(ctx))
+}
+
+// fanOut calls fn for every index in [0, n) using a bounded pool of workers and returns the
+// results in input order. Inputs that never get to run because ctx is done are given
+// failed(ctx.Err()) as their result.
+//   T - the result type
+//   ctx - cancels any work that has not started yet
+//   n - the number of inputs
+//   opts - worker and rate settings
+//   fn - performs the work for a single input
+//   failed - builds the result for an input that could not run
+func fanOut[T any](ctx context.Context, n int, opts FanOutOptions, fn func(i int) T, failed func(err error) T) []T {
+	results := make([]T, n)
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultFanOutWorkers
+	}
+	if workers > n {
+		workers = n
+	}
+	limiter := newWindowLimiter(opts.Requests, opts.Interval)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := limiter.wait(ctx); err != nil {
+					results[i] = failed(err)
+					continue
+				}
+				results[i] = fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,108 @@
+package lotrsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFanOutServer answers /character/{id}/quote and /movie/{id}/quote with a single quote
+// whose dialog is the id; ids starting with "bad" get a 500
+func newFanOutServer(inFlight, maxInFlight *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cur := atomic.AddInt64(inFlight, 1)
+		defer atomic.AddInt64(inFlight, -1)
+		for {
+			max := atomic.LoadInt64(maxInFlight)
+			if cur <= max || atomic.CompareAndSwapInt64(maxInFlight, max, cur) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		id := strings.Split(r.URL.Path, "/")[2]
+		if strings.HasPrefix(id, "bad") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `{"docs":[{"_id":"q-%s","dialog":"%s"}],"total":1,"limit":1000,"offset":0,"page":1,"pages":1}`, id, id)
+	}))
+}
+
+func TestQuotesForCharacters(t *testing.T) {
+	var inFlight, maxInFlight int64
+	ts := newFanOutServer(&inFlight, &maxInFlight)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+	characters := make([]Character, 20)
+	for i := range characters {
+		characters[i].ID = CharacterID(fmt.Sprintf("%024x", i))
+	}
+	characters[7].ID = "bad000000000000000000007"
+
+	results := client.QuotesForCharacters(context.Background(), characters, FanOutOptions{Workers: 3})
+
+	assert.Equal(t, len(results), len(characters))
+	for i, result := range results {
+		if i == 7 {
+			assert.NotNil(t, result.Err)
+			continue
+		}
+		assert.Nil(t, result.Err)
+		assert.Equal(t, len(result.Quotes), 1)
+		assert.Equal(t, result.Quotes[0].Dialog, string(characters[i].ID))
+		assert.Equal(t, result.Status.Total, 1)
+	}
+	assert.LessOrEqual(t, atomic.LoadInt64(&maxInFlight), int64(3))
+}
+
+func TestQuotesForMoviesRateCap(t *testing.T) {
+	var inFlight, maxInFlight int64
+	ts := newFanOutServer(&inFlight, &maxInFlight)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+	movies := make([]Movie, 6)
+	for i := range movies {
+		movies[i].ID = MovieID(fmt.Sprintf("%024x", i))
+	}
+
+	start := time.Now()
+	results := client.QuotesForMovies(context.Background(), movies, FanOutOptions{
+		Workers:  6,
+		Requests: 2,
+		Interval: 50 * time.Millisecond,
+	})
+
+	// 6 requests at 2 per 50ms needs at least two full windows
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	for i, result := range results {
+		assert.Nil(t, result.Err)
+		assert.Equal(t, result.Quotes[0].Dialog, string(movies[i].ID))
+	}
+}
+
+func TestQuotesForCharactersCancelled(t *testing.T) {
+	var inFlight, maxInFlight int64
+	ts := newFanOutServer(&inFlight, &maxInFlight)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := client.QuotesForCharacters(ctx, make([]Character, 3), FanOutOptions{})
+
+	assert.Equal(t, len(results), 3)
+	for _, result := range results {
+		assert.ErrorIs(t, result.Err, context.Canceled)
+	}
+}
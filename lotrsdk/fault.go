@@ -0,0 +1,411 @@
+package lotrsdk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFaultStatus    = http.StatusServiceUnavailable
+	defaultSlowBodyChunk  = 64
+	defaultSlowBodyDelay  = 10 * time.Millisecond
+	faultMaintenancePage  = "<!DOCTYPE html>\n<html><head><title>Maintenance</title></head><body><h1>the-one-api is down for maintenance</h1></body></html>\n"
+	faultEmptyDocumentSet = `{"docs":[],"total":0,"limit":1000,"offset":0,"page":1,"pages":1}`
+)
+
+// FaultKind is the kind of failure injected by a Fault
+type FaultKind int
+
+const (
+	// FaultNone lets the request through untouched
+	FaultNone FaultKind = iota
+	// FaultTimeout fails the request with a timeout error (FaultHandler: hangs, then answers 504)
+	FaultTimeout FaultKind = iota
+	// FaultRateLimit answers 429 with a Retry-After header, and X-RateLimit headers reporting the quota is exhausted
+	FaultRateLimit FaultKind = iota
+	// FaultServerError answers with a 5xx status
+	FaultServerError FaultKind = iota
+	// FaultTruncatedJSON cuts the real response body in half
+	FaultTruncatedJSON FaultKind = iota
+	// FaultHTMLError answers with an HTML error page instead of JSON
+	FaultHTMLError FaultKind = iota
+	// FaultSlowBody sends the real response body a few bytes at a time
+	FaultSlowBody FaultKind = iota
+)
+
+func (fk FaultKind) String() string {
+	switch fk {
+	case FaultNone:
+		return "none"
+	case FaultTimeout:
+		return "timeout"
+	case FaultRateLimit:
+		return "rate_limit"
+	case FaultServerError:
+		return "server_error"
+	case FaultTruncatedJSON:
+		return "truncated_json"
+	case FaultHTMLError:
+		return "html_error"
+	case FaultSlowBody:
+		return "slow_body"
+	}
+	return "unknown"
+}
+
+// Fault describes one injected failure
+type Fault struct {
+	Kind FaultKind
+	// Status is the status code of FaultServerError and FaultHTMLError; defaults to 503
+	Status int
+	// RetryAfter is the Retry-After header sent with FaultRateLimit; it is left out if zero
+	RetryAfter time.Duration
+	// Delay is how long FaultTimeout waits before failing, and how long FaultSlowBody waits between
+	// chunks (defaults to 10ms)
+	Delay time.Duration
+	// Repeat is the number of requests in a row that get this fault (a burst); defaults to 1
+	Repeat int
+}
+
+// FaultSchedule decides which Fault the next request gets
+// implementations must be safe for concurrent use
+type FaultSchedule interface {
+	Next() Fault
+}
+
+type sequenceSchedule struct {
+	mu     sync.Mutex
+	faults []Fault
+	loop   bool
+	next   int
+	left   int
+}
+
+// FaultSequence returns a FaultSchedule that hands out the provided faults in order (each one Repeat times),
+// then lets every request through
+//   faults - the faults to inject; use Fault{Kind: FaultNone} for requests that should succeed
+func FaultSequence(faults ...Fault) FaultSchedule {
+	return &sequenceSchedule{faults: faults}
+}
+
+// FaultCycle is like FaultSequence, but starts over once every fault was handed out
+//   faults - the faults to inject; use Fault{Kind: FaultNone} for requests that should succeed
+func FaultCycle(faults ...Fault) FaultSchedule {
+	return &sequenceSchedule{faults: faults, loop: true}
+}
+
+func (ss *sequenceSchedule) Next() Fault {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.left > 0 {
+		ss.left--
+		return ss.faults[ss.next-1]
+	}
+	if ss.next >= len(ss.faults) {
+		if !ss.loop || len(ss.faults) == 0 {
+			return Fault{}
+		}
+		ss.next = 0
+	}
+	fault := ss.faults[ss.next]
+	ss.next++
+	ss.left = fault.Repeat - 1
+	return fault
+}
+
+type randomSchedule struct {
+	mu      sync.Mutex
+	rng     *rand.Rand
+	rate    float64
+	faults  []Fault
+	current Fault
+	left    int
+}
+
+// RandomFaults returns a FaultSchedule where each request fails with probability rate, with a fault
+// picked from faults (a picked fault then lasts for its Repeat requests). The same seed always gives
+// the same sequence of faults, so a failing test can be reproduced exactly.
+//   seed - the seed of the random number generator
+//   rate - the probability, from 0 to 1, that a request gets a fault
+//   faults - the faults to pick from
+func RandomFaults(seed int64, rate float64, faults ...Fault) FaultSchedule {
+	return &randomSchedule{
+		rng:    rand.New(rand.NewSource(seed)),
+		rate:   rate,
+		faults: faults,
+	}
+}
+
+func (rs *randomSchedule) Next() Fault {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.left > 0 {
+		rs.left--
+		return rs.current
+	}
+	if len(rs.faults) == 0 || rs.rng.Float64() >= rs.rate {
+		return Fault{}
+	}
+	rs.current = rs.faults[rs.rng.Intn(len(rs.faults))]
+	rs.left = rs.current.Repeat - 1
+	return rs.current
+}
+
+// faultTimeoutError is the error of FaultTimeout; it is a net.Error, like the timeouts of http.Client
+type faultTimeoutError struct{}
+
+func (faultTimeoutError) Error() string   { return "injected fault: timeout awaiting response" }
+func (faultTimeoutError) Timeout() bool   { return true }
+func (faultTimeoutError) Temporary() bool { return true }
+
+// FaultTransport is an http.RoundTripper that injects failures following a FaultSchedule,
+// for testing how code using a Client copes with the-one-api misbehaving:
+//   client := NewClient(token, WithHTTPClient(&http.Client{Transport: NewFaultTransport(nil, schedule)}))
+type FaultTransport struct {
+	base     http.RoundTripper
+	schedule FaultSchedule
+}
+
+// NewFaultTransport creates a FaultTransport
+//   base - the transport used for the requests that reach the server; defaults to http.DefaultTransport
+//   schedule - decides which fault each request gets
+func NewFaultTransport(base http.RoundTripper, schedule FaultSchedule) *FaultTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &FaultTransport{
+		base:     base,
+		schedule: schedule,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (ft *FaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault := ft.schedule.Next()
+	if fault.Kind == FaultTimeout {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		if err := sleepContext(req.Context(), fault.Delay); err != nil {
+			return nil, err
+		}
+		return nil, faultTimeoutError{}
+	}
+	return fault.apply(req, func() (*http.Response, error) {
+		return ft.base.RoundTrip(req)
+	})
+}
+
+// FaultHandler returns an http.Handler that injects the same failures as FaultTransport in front of next,
+// for use with httptest.NewServer
+//   next - the handler answering the requests that get through; if nil, it answers with no documents
+//   schedule - decides which fault each request gets
+func FaultHandler(next http.Handler, schedule FaultSchedule) http.Handler {
+	if next == nil {
+		next = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			io.WriteString(w, faultEmptyDocumentSet)
+		})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fault := schedule.Next()
+		if fault.Kind == FaultTimeout {
+			// hang until the client gives up (or Delay is over)
+			if fault.Delay > 0 {
+				sleepContext(r.Context(), fault.Delay)
+			} else {
+				<-r.Context().Done()
+			}
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+
+		resp, _ := fault.apply(r, func() (*http.Response, error) {
+			buffered := &bufferedResponseWriter{header: http.Header{}}
+			next.ServeHTTP(buffered, r)
+			return buffered.response(r), nil
+		})
+		defer resp.Body.Close()
+
+		for key, values := range resp.Header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(resp.StatusCode)
+		flusher, _ := w.(http.Flusher)
+		buf := make([]byte, defaultSlowBodyChunk)
+		for {
+			n, err := resp.Body.Read(buf)
+			if n > 0 {
+				if _, err := w.Write(buf[:n]); err != nil {
+					return
+				}
+				if flusher != nil {
+					flusher.Flush()
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	})
+}
+
+// bufferedResponseWriter keeps the response written by a handler in memory, so a fault can be applied to it
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (bw *bufferedResponseWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *bufferedResponseWriter) WriteHeader(status int) {
+	if bw.status == 0 {
+		bw.status = status
+	}
+}
+
+func (bw *bufferedResponseWriter) Write(b []byte) (int, error) {
+	bw.WriteHeader(http.StatusOK)
+	return bw.body.Write(b)
+}
+
+// response returns what was written as an *http.Response, with the defaults net/http would add
+func (bw *bufferedResponseWriter) response(req *http.Request) *http.Response {
+	bw.WriteHeader(http.StatusOK)
+	header := bw.header.Clone()
+	if header.Get("Content-Type") == "" && bw.body.Len() > 0 {
+		header.Set("Content-Type", http.DetectContentType(bw.body.Bytes()))
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", bw.status, http.StatusText(bw.status)),
+		StatusCode:    bw.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(bw.body.Bytes())),
+		ContentLength: int64(bw.body.Len()),
+		Request:       req,
+	}
+}
+
+// apply returns the response to req with the fault injected; upstream gets the real response
+func (f Fault) apply(req *http.Request, upstream func() (*http.Response, error)) (*http.Response, error) {
+	status := f.Status
+	if status == 0 {
+		status = defaultFaultStatus
+	}
+
+	switch f.Kind {
+	case FaultRateLimit:
+		header := http.Header{}
+		if f.RetryAfter > 0 {
+			header.Set("Retry-After", strconv.Itoa(int((f.RetryAfter+time.Second-1)/time.Second)))
+		}
+		// the quota resets when the client may retry, or after the API's window if that is not given
+		reset := f.RetryAfter
+		if reset <= 0 {
+			reset = defaultQuotaWindow
+		}
+		header.Set("X-RateLimit-Limit", strconv.Itoa(defaultQuotaLimit))
+		header.Set("X-RateLimit-Remaining", "0")
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(reset).Unix(), 10))
+		return faultResponse(req, http.StatusTooManyRequests, header, "application/json; charset=utf-8",
+			`{"success":false,"message":"Too many requests, please try again later."}`), nil
+	case FaultServerError:
+		return faultResponse(req, status, http.Header{}, "application/json; charset=utf-8",
+			fmt.Sprintf(`{"success":false,"message":"%s"}`, http.StatusText(status))), nil
+	case FaultHTMLError:
+		return faultResponse(req, status, http.Header{}, "text/html; charset=utf-8", faultMaintenancePage), nil
+	}
+
+	resp, err := upstream()
+	if err != nil {
+		return nil, err
+	}
+	switch f.Kind {
+	case FaultTruncatedJSON:
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = body[:len(body)/2]
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		resp.ContentLength = int64(len(body))
+		resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	case FaultSlowBody:
+		delay := f.Delay
+		if delay <= 0 {
+			delay = defaultSlowBodyDelay
+		}
+		resp.Body = &slowBody{ctx: req.Context(), body: resp.Body, delay: delay}
+	}
+	return resp, nil
+}
+
+func faultResponse(req *http.Request, status int, header http.Header, contentType, body string) *http.Response {
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// slowBody hands out the body a chunk at a time, waiting delay before each chunk
+type slowBody struct {
+	ctx   context.Context
+	body  io.ReadCloser
+	delay time.Duration
+}
+
+func (sb *slowBody) Read(p []byte) (int, error) {
+	if err := sleepContext(sb.ctx, sb.delay); err != nil {
+		return 0, err
+	}
+	if len(p) > defaultSlowBodyChunk {
+		p = p[:defaultSlowBodyChunk]
+	}
+	return sb.body.Read(p)
+}
+
+func (sb *slowBody) Close() error {
+	return sb.body.Close()
+}
+
+// sleepContext waits for d, or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,159 @@
+package lotrsdk
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newBookHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(bookData))
+	})
+}
+
+func kinds(schedule FaultSchedule, n int) []FaultKind {
+	result := make([]FaultKind, n)
+	for i := range result {
+		result[i] = schedule.Next().Kind
+	}
+	return result
+}
+
+func TestFaultSequence(t *testing.T) {
+	schedule := FaultSequence(
+		Fault{Kind: FaultServerError, Repeat: 3},
+		Fault{Kind: FaultNone},
+		Fault{Kind: FaultRateLimit},
+	)
+	assert.Equal(t, kinds(schedule, 7), []FaultKind{
+		FaultServerError, FaultServerError, FaultServerError, FaultNone, FaultRateLimit, FaultNone, FaultNone,
+	})
+
+	cycle := FaultCycle(Fault{Kind: FaultTimeout}, Fault{Kind: FaultNone})
+	assert.Equal(t, kinds(cycle, 4), []FaultKind{FaultTimeout, FaultNone, FaultTimeout, FaultNone})
+}
+
+func TestRandomFaultsAreReproducible(t *testing.T) {
+	faults := []Fault{{Kind: FaultServerError, Repeat: 2}, {Kind: FaultHTMLError}, {Kind: FaultTruncatedJSON}}
+	first := kinds(RandomFaults(42, 0.5, faults...), 50)
+	second := kinds(RandomFaults(42, 0.5, faults...), 50)
+	assert.Equal(t, first, second)
+	assert.Contains(t, first, FaultNone)
+	assert.Contains(t, first, FaultServerError)
+
+	assert.Equal(t, kinds(RandomFaults(42, 0, faults...), 10), make([]FaultKind, 10))
+}
+
+func TestFaultTransport(t *testing.T) {
+	ts := httptest.NewServer(newBookHandler())
+	defer ts.Close()
+	schedule := FaultSequence(
+		Fault{Kind: FaultTimeout},
+		Fault{Kind: FaultRateLimit, RetryAfter: 30 * time.Second},
+		Fault{Kind: FaultServerError, Status: http.StatusBadGateway},
+		Fault{Kind: FaultTruncatedJSON},
+		Fault{Kind: FaultHTMLError},
+		Fault{Kind: FaultSlowBody, Delay: time.Millisecond},
+	)
+	transport := NewFaultTransport(nil, schedule)
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithHTTPClient(&http.Client{Transport: transport}))
+
+	_, _, err := client.Books()
+	var netErr net.Error
+	assert.True(t, errors.As(err, &netErr) && netErr.Timeout())
+
+	meta := &ResponseMeta{}
+	_, _, err = client.Books(WithResponseMeta(meta))
+	assert.NotNil(t, err)
+	assert.Equal(t, meta.StatusCode, http.StatusTooManyRequests)
+	assert.Equal(t, meta.Header.Get("Retry-After"), "30")
+	assert.True(t, meta.HasRateLimit)
+	assert.Equal(t, meta.RateLimit.Limit, defaultQuotaLimit)
+	assert.Equal(t, meta.RateLimit.Remaining, 0)
+	assert.InDelta(t, float64(30*time.Second), float64(time.Until(meta.RateLimit.Reset)), float64(2*time.Second))
+
+	_, _, err = client.Books(WithResponseMeta(meta))
+	assert.NotNil(t, err)
+	assert.Equal(t, meta.StatusCode, http.StatusBadGateway)
+
+	_, _, err = client.Books()
+	assert.NotNil(t, err)
+
+	_, _, err = client.Books(WithResponseMeta(meta))
+	assert.NotNil(t, err)
+	assert.Equal(t, meta.StatusCode, http.StatusServiceUnavailable)
+	assert.Equal(t, meta.Header.Get("Content-Type"), "text/html; charset=utf-8")
+
+	books, _, err := client.Books()
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+}
+
+func TestFaultTransportWithRetries(t *testing.T) {
+	ts := httptest.NewServer(newBookHandler())
+	defer ts.Close()
+	schedule := FaultSequence(Fault{Kind: FaultServerError, Repeat: 2})
+	client := NewClient("fake-token",
+		WithBaseURL(ts.URL),
+		WithHTTPClient(&http.Client{Transport: NewFaultTransport(nil, schedule)}),
+		WithMiddleware(RetryMiddleware(RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})),
+	)
+
+	meta := &ResponseMeta{}
+	books, _, err := client.Books(WithResponseMeta(meta))
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+	assert.Equal(t, meta.Retries, 2)
+}
+
+func TestFaultHandler(t *testing.T) {
+	schedule := FaultSequence(
+		Fault{Kind: FaultTruncatedJSON},
+		Fault{Kind: FaultHTMLError, Status: http.StatusInternalServerError},
+		Fault{Kind: FaultSlowBody, Delay: time.Millisecond},
+		Fault{Kind: FaultTimeout},
+	)
+	ts := httptest.NewServer(FaultHandler(newBookHandler(), schedule))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+	_, _, err := client.Books()
+	assert.NotNil(t, err)
+
+	meta := &ResponseMeta{}
+	_, _, err = client.Books(WithResponseMeta(meta))
+	assert.NotNil(t, err)
+	assert.Equal(t, meta.StatusCode, http.StatusInternalServerError)
+
+	books, _, err := client.Books()
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, err = client.Books(WithContext(ctx))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the schedule is over, so requests go through
+	books, _, err = client.Books()
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+}
+
+func TestFaultHandlerDefault(t *testing.T) {
+	ts := httptest.NewServer(FaultHandler(nil, FaultSequence()))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+	books, status, err := client.Books()
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 0)
+	assert.Equal(t, status.Total, 0)
+}
@@ -3,6 +3,7 @@ package lotrsdk
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -228,23 +229,19 @@ func Offset(value int) Filter {
 type Filters []Filter
 
 func (fs Filters) GenerateRawQuery() (string, error) {
-	if len(fs) == 0 {
-		return "", nil
-	}
-
 	sb := strings.Builder{}
-	str, err := fs[0].GenerateRawQuery()
-	if err != nil {
-		return "", fmt.Errorf("failed to generate query params: %w", err)
-	}
-	sb.WriteString(str)
-
-	for _, elt := range fs[1:] {
-		sb.WriteByte('&')
+	for _, elt := range fs {
 		str, err := elt.GenerateRawQuery()
 		if err != nil {
 			return "", fmt.Errorf("failed to generate query params: %w", err)
 		}
+		// call options (see call.go) do not add anything to the query params
+		if str == "" {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('&')
+		}
 		sb.WriteString(str)
 	}
 
@@ -260,3 +257,15 @@ func MergeFilters(filters ...Filter) Filter {
 	}
 	return result
 }
+
+// canonicalQuery sorts the params of a raw query so that the same filters passed in a different
+// order produce the same string; used to key caches and recordings
+//   rawQuery - a query generated by a Filter
+func canonicalQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	parts := strings.Split(rawQuery, "&")
+	sort.Strings(parts)
+	return strings.Join(parts, "&")
+}
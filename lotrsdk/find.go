@@ -0,0 +1,353 @@
+package lotrsdk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// this file contains the fuzzy character name lookup ("gandolf" finds Gandalf), which searches a
+// local index of every character rather than calling the API per query
+
+const (
+	// defaultCharacterMatches is the number of matches FindCharacter returns
+	defaultCharacterMatches = 10
+	// minCharacterScore is the lowest score considered a match
+	minCharacterScore = 0.5
+	// indexPageSize is the limit used for each page of records fetched to build an index
+	indexPageSize = 1000
+)
+
+// CharacterMatch is a character found by a fuzzy name lookup
+type CharacterMatch struct {
+	Character Character
+	// Score is how well the name matched, from 1 (exactly) down to 0.5
+	Score float64
+}
+
+// CharacterIndex finds characters by approximate name; it is safe for concurrent use
+type CharacterIndex struct {
+	entries []characterEntry
+}
+
+// characterEntry is a character with its name prepared for matching
+type characterEntry struct {
+	character Character
+	name      string
+	tokens    []string
+}
+
+// NewCharacterIndex builds an index of characters for Find
+//   characters - the characters to search, typically every one from Client.Characters
+func NewCharacterIndex(characters []Character) *CharacterIndex {
+	index := &CharacterIndex{entries: make([]characterEntry, 0, len(characters))}
+	for _, character := range characters {
+		name := normalizeName(character.Name)
+		if name == "" {
+			continue
+		}
+		index.entries = append(index.entries, characterEntry{
+			character: character,
+			name:      name,
+			tokens:    strings.Fields(name),
+		})
+	}
+	return index
+}
+
+// Len returns the number of characters in the index
+func (ci *CharacterIndex) Len() int {
+	return len(ci.entries)
+}
+
+// Find returns the n characters whose names best match query, best first
+// case, accents and punctuation are ignored, and names are matched whole, by prefix ("frodo" finds
+// Frodo Baggins), word by word ("baggins") and allowing for typos ("gandolf" finds Gandalf)
+//   query - the name, or part of it, as typed
+//   n - the maximum number of matches returned; every match is returned if n <= 0
+func (ci *CharacterIndex) Find(query string, n int) []CharacterMatch {
+	matches := make([]CharacterMatch, 0)
+	query = normalizeName(query)
+	if query == "" {
+		return matches
+	}
+	queryTokens := strings.Fields(query)
+
+	type scored struct {
+		match CharacterMatch
+		name  string
+	}
+	found := make([]scored, 0)
+	for _, entry := range ci.entries {
+		if score := nameScore(query, queryTokens, entry); score >= minCharacterScore {
+			found = append(found, scored{match: CharacterMatch{Character: entry.character, Score: score}, name: entry.name})
+		}
+	}
+
+	// equal scores favor the shorter name, which matched more of itself
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].match.Score != found[j].match.Score {
+			return found[i].match.Score > found[j].match.Score
+		}
+		if len(found[i].name) != len(found[j].name) {
+			return len(found[i].name) < len(found[j].name)
+		}
+		return found[i].name < found[j].name
+	})
+	for _, f := range found {
+		matches = append(matches, f.match)
+	}
+	if n > 0 && len(matches) > n {
+		matches = matches[:n]
+	}
+	return matches
+}
+
+// nameScore rates how well query matches a character's name, from 0 to 1
+// the best of a whole name match (with typos), a prefix match and a word by word match is used
+func nameScore(query string, queryTokens []string, entry characterEntry) float64 {
+	if query == entry.name {
+		return 1
+	}
+
+	best := 0.8 * similarity(query, entry.name)
+	if strings.HasPrefix(entry.name, query) {
+		best = maxScore(best, 0.9+0.1*float64(len(query))/float64(len(entry.name)))
+	}
+
+	// every word of the query is matched against its best word of the name
+	var total float64
+	matched := 0
+	for _, queryToken := range queryTokens {
+		var tokenBest float64
+		for _, token := range entry.tokens {
+			tokenBest = maxScore(tokenBest, tokenScore(queryToken, token))
+		}
+		if tokenBest > 0 {
+			matched++
+		}
+		total += tokenBest
+	}
+	if matched > 0 {
+		// names with words the query left out rank a little lower
+		coverage := float64(minInt(matched, len(entry.tokens))) / float64(len(entry.tokens))
+		best = maxScore(best, 0.95*(total/float64(len(queryTokens)))*(0.9+0.1*coverage))
+	}
+	return best
+}
+
+// tokenScore rates how well a word of the query matches a word of a name, from 0 to 1
+func tokenScore(queryToken, token string) float64 {
+	switch {
+	case queryToken == token:
+		return 1
+	case strings.HasPrefix(token, queryToken):
+		return 0.8 + 0.2*float64(len(queryToken))/float64(len(token))
+	}
+	// a typo in a short word changes too much of it to be a match
+	if len([]rune(queryToken)) < 3 {
+		return 0
+	}
+	if sim := similarity(queryToken, token); sim >= 0.6 {
+		return 0.85 * sim
+	}
+	return 0
+}
+
+// similarity returns 1 minus the edit distance between a and b relative to the longer one
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(ra, rb))/float64(longest)
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions of
+// adjacent runes needed to turn a into b (the optimal string alignment distance)
+func editDistance(a, b []rune) int {
+	// rows[i][j] is the distance between a[:i] and b[:j]; only the last three rows are needed
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = minInt(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// normalizeName lower cases name, strips its accents ("Éowyn" is "eowyn") and turns everything
+// but letters and digits into single spaces
+func normalizeName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(name) {
+		if folded, ok := accentFolds[r]; ok {
+			r = folded
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		} else if r != '\'' {
+			// apostrophes join their word ("Gil-galad's" is "gil galads")
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// accentFolds maps the accented letters found in character names to their plain letter
+var accentFolds = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a', 'ã': 'a', 'å': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'ö': 'o', 'õ': 'o', 'ø': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ý': 'y', 'ÿ': 'y', 'ñ': 'n', 'ç': 'c',
+	'’': '\'',
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxScore(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// characterIndexCache holds the CharacterIndex a client builds on first use
+// mu only guards the fields, and is never held while the characters are fetched
+type characterIndexCache struct {
+	mu    sync.Mutex
+	index *CharacterIndex
+	// building is the build in progress, if any; concurrent callers share it
+	building *indexBuild
+}
+
+// indexBuild is a single fetch of every character, shared by the callers waiting for it
+type indexBuild struct {
+	// done is closed once index and err are set
+	done   chan struct{}
+	cancel context.CancelFunc
+	// waiters is the number of callers still waiting; guarded by characterIndexCache.mu
+	waiters int
+
+	index *CharacterIndex
+	err   error
+}
+
+func (c *client) FindCharacter(ctx context.Context, query string) ([]CharacterMatch, error) {
+	index, err := c.CharacterIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return index.Find(query, defaultCharacterMatches), nil
+}
+
+func (c *client) CharacterIndex(ctx context.Context) (*CharacterIndex, error) {
+	c.characterIndex.mu.Lock()
+	if index := c.characterIndex.index; index != nil {
+		c.characterIndex.mu.Unlock()
+		return index, nil
+	}
+	build := c.joinCharacterIndexBuild(ctx)
+	c.characterIndex.mu.Unlock()
+	return c.waitCharacterIndexBuild(ctx, build)
+}
+
+func (c *client) RefreshCharacterIndex(ctx context.Context) error {
+	c.characterIndex.mu.Lock()
+	build := c.joinCharacterIndexBuild(ctx)
+	c.characterIndex.mu.Unlock()
+	_, err := c.waitCharacterIndexBuild(ctx, build)
+	return err
+}
+
+// joinCharacterIndexBuild returns the build in progress, starting one in the background if there is
+// none, and counts the caller as waiting for it; c.characterIndex.mu must be held
+// the build is not tied to any one caller, and is only cancelled once every caller has given up
+func (c *client) joinCharacterIndexBuild(ctx context.Context) *indexBuild {
+	cache := c.characterIndex
+	if cache.building == nil {
+		buildCtx, cancel := context.WithCancel(detachedContext{parent: ctx})
+		build := &indexBuild{
+			done:   make(chan struct{}),
+			cancel: cancel,
+		}
+		cache.building = build
+		go func() {
+			defer cancel()
+			build.index, build.err = c.buildCharacterIndex(buildCtx)
+
+			cache.mu.Lock()
+			if build.err == nil {
+				cache.index = build.index
+			}
+			if cache.building == build {
+				cache.building = nil
+			}
+			cache.mu.Unlock()
+			close(build.done)
+		}()
+	}
+	cache.building.waiters++
+	return cache.building
+}
+
+// waitCharacterIndexBuild waits for build to finish, or for ctx to be done
+func (c *client) waitCharacterIndexBuild(ctx context.Context, build *indexBuild) (*CharacterIndex, error) {
+	select {
+	case <-build.done:
+		return build.index, build.err
+	case <-ctx.Done():
+		cache := c.characterIndex
+		cache.mu.Lock()
+		build.waiters--
+		if build.waiters == 0 && cache.building == build {
+			// nobody is waiting for the build anymore
+			cache.building = nil
+			build.cancel()
+		}
+		cache.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// buildCharacterIndex fetches every character and builds their index
+func (c *client) buildCharacterIndex(ctx context.Context) (*CharacterIndex, error) {
+	characters, err := allPages(ctx, c.tracer, "Characters", indexPageSize, c.Characters)
+	if err != nil {
+		return nil, fmt.Errorf("building the character index failed: %w", err)
+	}
+	return NewCharacterIndex(characters), nil
+}
@@ -0,0 +1,246 @@
+package lotrsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var findCharacters = []Character{
+	{ID: "5cd99d4bde30eff6ebccfea0", Name: "Gandalf"},
+	{ID: "5cd99d4bde30eff6ebccfc15", Name: "Frodo Baggins"},
+	{ID: "5cd99d4bde30eff6ebccfc7c", Name: "Bilbo Baggins"},
+	{ID: "5cd99d4bde30eff6ebccfd0d", Name: "Samwise Gamgee"},
+	{ID: "5cd99d4bde30eff6ebccfd06", Name: "Gamling"},
+	{ID: "5cd99d4bde30eff6ebccfbe6", Name: "Aragorn II Elessar"},
+	{ID: "5cd99d4bde30eff6ebccfd23", Name: "Éowyn"},
+	{ID: "5cd99d4bde30eff6ebccfd81", Name: "Gil-galad"},
+	{ID: "5cd99d4bde30eff6ebccfe2e", Name: "Théoden"},
+	{ID: "5cd99d4bde30eff6ebccfcc8", Name: "Legolas"},
+	{ID: "5cd99d4bde30eff6ebccfd0e", Name: "Sméagol"},
+	{ID: "5cd99d4bde30eff6ebccfc57", Name: ""},
+}
+
+func matchNames(matches []CharacterMatch) []string {
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		names = append(names, match.Character.Name)
+	}
+	return names
+}
+
+func TestCharacterIndexFind(t *testing.T) {
+	index := NewCharacterIndex(findCharacters)
+	assert.Equal(t, index.Len(), 11)
+
+	tests := []struct {
+		query string
+		first string
+	}{
+		{"Gandalf", "Gandalf"},
+		{"gandolf", "Gandalf"},
+		{"frodo", "Frodo Baggins"},
+		{"FRODO BAGGINS", "Frodo Baggins"},
+		{"frod", "Frodo Baggins"},
+		{"fordo", "Frodo Baggins"},
+		{"baggins bilbo", "Bilbo Baggins"},
+		{"sam", "Samwise Gamgee"},
+		{"eowyn", "Éowyn"},
+		{"theoden", "Théoden"},
+		{"gil galad", "Gil-galad"},
+		{"aragorn", "Aragorn II Elessar"},
+		{"elessar", "Aragorn II Elessar"},
+		{"legolass", "Legolas"},
+	}
+	for _, test := range tests {
+		matches := index.Find(test.query, 3)
+		if assert.NotEmpty(t, matches, test.query) {
+			assert.Equal(t, matches[0].Character.Name, test.first, test.query)
+		}
+	}
+
+	exact := index.Find("gandalf", 1)
+	assert.Equal(t, exact[0].Score, 1.0)
+	typo := index.Find("gandolf", 1)
+	assert.Less(t, typo[0].Score, 1.0)
+	assert.GreaterOrEqual(t, typo[0].Score, minCharacterScore)
+}
+
+func TestCharacterIndexFindRanking(t *testing.T) {
+	index := NewCharacterIndex(findCharacters)
+
+	// both Bagginses match equally, so the shorter, then alphabetical, name comes first
+	assert.Equal(t, matchNames(index.Find("baggins", 0)), []string{"Bilbo Baggins", "Frodo Baggins"})
+	// only the best n are returned
+	assert.Equal(t, len(index.Find("ga", 0)) > 1, true)
+	assert.Equal(t, len(index.Find("ga", 1)), 1)
+
+	matches := index.Find("gam", 0)
+	for i := 1; i < len(matches); i++ {
+		assert.GreaterOrEqual(t, matches[i-1].Score, matches[i].Score)
+	}
+
+	assert.Equal(t, len(index.Find("", 5)), 0)
+	assert.Equal(t, len(index.Find("  -- ", 5)), 0)
+	assert.Equal(t, len(index.Find("zzzzzz", 5)), 0)
+	// a typo in a very short word is too much of it to match
+	assert.Equal(t, len(index.Find("xi", 5)), 0)
+}
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, normalizeName("  Gil-galad "), "gil galad")
+	assert.Equal(t, normalizeName("Éowyn"), "eowyn")
+	assert.Equal(t, normalizeName("Gríma Wormtongue"), "grima wormtongue")
+	assert.Equal(t, normalizeName("Gil-galad's"), "gil galads")
+	assert.Equal(t, normalizeName("Aragorn II (Elessar)"), "aragorn ii elessar")
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, editDistance([]rune("gandolf"), []rune("gandalf")), 1)
+	assert.Equal(t, editDistance([]rune("fordo"), []rune("frodo")), 1)
+	assert.Equal(t, editDistance([]rune(""), []rune("sam")), 3)
+	assert.Equal(t, editDistance([]rune("sam"), []rune("")), 3)
+	assert.Equal(t, editDistance([]rune("kitten"), []rune("sitting")), 3)
+}
+
+// newCharacterPagesServer serves characters pageSize at a time and counts the requests
+func newCharacterPagesServer(characters []Character, pageSize int, requests *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(requests, 1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pages := (len(characters) + pageSize - 1) / pageSize
+		start, end := (page-1)*pageSize, page*pageSize
+		if start > len(characters) {
+			start = len(characters)
+		}
+		if end > len(characters) {
+			end = len(characters)
+		}
+		docs, _ := json.Marshal(characters[start:end])
+		fmt.Fprintf(w, `{"docs":%s,"total":%d,"limit":%d,"offset":%d,"page":%d,"pages":%d}`, docs, len(characters), pageSize, start, page, pages)
+	}))
+}
+
+func TestFindCharacter(t *testing.T) {
+	var requests int64
+	ts := newCharacterPagesServer(findCharacters, 5, &requests)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+	matches, err := client.FindCharacter(context.Background(), "gandolf")
+	assert.Nil(t, err)
+	assert.Equal(t, matches[0].Character.Name, "Gandalf")
+	assert.Equal(t, matches[0].Character.ID, CharacterID("5cd99d4bde30eff6ebccfea0"))
+	// every page was fetched
+	assert.Equal(t, atomic.LoadInt64(&requests), int64(3))
+
+	// later lookups use the index
+	matches, err = client.FindCharacter(context.Background(), "smeagol")
+	assert.Nil(t, err)
+	assert.Equal(t, matches[0].Character.Name, "Sméagol")
+	index, err := client.CharacterIndex(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, index.Len(), 11)
+	assert.Equal(t, atomic.LoadInt64(&requests), int64(3))
+
+	assert.Nil(t, client.RefreshCharacterIndex(context.Background()))
+	assert.Equal(t, atomic.LoadInt64(&requests), int64(6))
+}
+
+func TestFindCharacterFailure(t *testing.T) {
+	var requests, failing int64 = 0, 1
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		if atomic.LoadInt64(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		docs, _ := json.Marshal(findCharacters)
+		fmt.Fprintf(w, `{"docs":%s,"total":%d,"limit":1000,"offset":0,"page":1,"pages":1}`, docs, len(findCharacters))
+	}))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+	_, err := client.FindCharacter(context.Background(), "frodo")
+	assert.NotNil(t, err)
+
+	// a failure is not cached
+	atomic.StoreInt64(&failing, 0)
+	matches, err := client.FindCharacter(context.Background(), "frodo")
+	assert.Nil(t, err)
+	assert.Equal(t, matches[0].Character.Name, "Frodo Baggins")
+
+	// a failed refresh keeps the previous index
+	atomic.StoreInt64(&failing, 1)
+	assert.NotNil(t, client.RefreshCharacterIndex(context.Background()))
+	matches, err = client.FindCharacter(context.Background(), "frodo")
+	assert.Nil(t, err)
+	assert.Equal(t, matches[0].Character.Name, "Frodo Baggins")
+	assert.Equal(t, atomic.LoadInt64(&requests), int64(3))
+}
+
+func TestFindCharacterConcurrent(t *testing.T) {
+	var requests int64
+	ts := newCharacterPagesServer(findCharacters, 1000, &requests)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+	done := make(chan []CharacterMatch)
+	for i := 0; i < 10; i++ {
+		go func() {
+			matches, _ := client.FindCharacter(context.Background(), "legolas")
+			done <- matches
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		matches := <-done
+		assert.Equal(t, matches[0].Character.Name, "Legolas")
+	}
+	// the index is only built once
+	assert.Equal(t, atomic.LoadInt64(&requests), int64(1))
+}
+
+func TestFindCharacterDoesNotWaitForFetch(t *testing.T) {
+	var blocked int64
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt64(&blocked) == 1 {
+			<-release
+		}
+		docs, _ := json.Marshal(findCharacters)
+		fmt.Fprintf(w, `{"docs":%s,"total":%d,"limit":1000,"offset":0,"page":1,"pages":1}`, docs, len(findCharacters))
+	}))
+	defer ts.Close()
+	defer close(release)
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+	_, err := client.CharacterIndex(context.Background())
+	assert.Nil(t, err)
+
+	// a slow refresh does not hold up lookups in the current index
+	atomic.StoreInt64(&blocked, 1)
+	refreshed := make(chan error, 1)
+	go func() {
+		refreshed <- client.RefreshCharacterIndex(context.Background())
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	matches, err := client.FindCharacter(ctx, "legolas")
+	assert.Nil(t, err)
+	assert.Equal(t, matches[0].Character.Name, "Legolas")
+
+	// a caller waiting for a fetch stops when its context is done
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, client.RefreshCharacterIndex(ctx), context.DeadlineExceeded)
+
+	release <- struct{}{}
+	assert.Nil(t, <-refreshed)
+}
@@ -0,0 +1,159 @@
+package lotrsdk
+
+import (
+	"errors"
+	"fmt"
+)
+
+// this file contains the ID types of the models, which keep a character ID from being passed where
+// a movie ID belongs; the-one-api IDs are MongoDB ObjectIDs (24 hexadecimal characters)
+
+// ErrInvalidID is returned (wrapped) for an ID that is not an ObjectID, or a nil model pointer
+var ErrInvalidID = errors.New("invalid ID")
+
+const objectIDLength = 24
+
+type BookID string
+type MovieID string
+type CharacterID string
+type QuoteID string
+type ChapterID string
+
+// Validate returns an error wrapping ErrInvalidID if the ID is not an ObjectID
+func (id BookID) Validate() error {
+	return validateID("book", string(id))
+}
+
+// Validate returns an error wrapping ErrInvalidID if the ID is not an ObjectID
+func (id MovieID) Validate() error {
+	return validateID("movie", string(id))
+}
+
+// Validate returns an error wrapping ErrInvalidID if the ID is not an ObjectID
+func (id CharacterID) Validate() error {
+	return validateID("character", string(id))
+}
+
+// Validate returns an error wrapping ErrInvalidID if the ID is not an ObjectID
+func (id QuoteID) Validate() error {
+	return validateID("quote", string(id))
+}
+
+// Validate returns an error wrapping ErrInvalidID if the ID is not an ObjectID
+func (id ChapterID) Validate() error {
+	return validateID("chapter", string(id))
+}
+
+// ParseBookID returns s as a BookID, or an error wrapping ErrInvalidID if it is not an ObjectID
+//   s - the ID
+func ParseBookID(s string) (BookID, error) {
+	return BookID(s), BookID(s).Validate()
+}
+
+// ParseMovieID returns s as a MovieID, or an error wrapping ErrInvalidID if it is not an ObjectID
+//   s - the ID
+func ParseMovieID(s string) (MovieID, error) {
+	return MovieID(s), MovieID(s).Validate()
+}
+
+// ParseCharacterID returns s as a CharacterID, or an error wrapping ErrInvalidID if it is not an ObjectID
+//   s - the ID
+func ParseCharacterID(s string) (CharacterID, error) {
+	return CharacterID(s), CharacterID(s).Validate()
+}
+
+// ParseQuoteID returns s as a QuoteID, or an error wrapping ErrInvalidID if it is not an ObjectID
+//   s - the ID
+func ParseQuoteID(s string) (QuoteID, error) {
+	return QuoteID(s), QuoteID(s).Validate()
+}
+
+// ParseChapterID returns s as a ChapterID, or an error wrapping ErrInvalidID if it is not an ObjectID
+//   s - the ID
+func ParseChapterID(s string) (ChapterID, error) {
+	return ChapterID(s), ChapterID(s).Validate()
+}
+
+func validateID(kind, id string) error {
+	if len(id) != objectIDLength {
+		return fmt.Errorf("%w: %s ID %q is not %d characters long", ErrInvalidID, kind, id, objectIDLength)
+	}
+	for _, r := range id {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f' || 'A' <= r && r <= 'F') {
+			return fmt.Errorf("%w: %s ID %q is not hexadecimal", ErrInvalidID, kind, id)
+		}
+	}
+	return nil
+}
+
+// BookRef is what the Client methods on a book's sub-resources accept: a BookID or a *Book
+type BookRef interface {
+	bookID() (BookID, error)
+}
+
+// MovieRef is what the Client methods on a movie's sub-resources accept: a MovieID or a *Movie
+type MovieRef interface {
+	movieID() (MovieID, error)
+}
+
+// CharacterRef is what the Client methods on a character's sub-resources accept: a CharacterID or a *Character
+type CharacterRef interface {
+	characterID() (CharacterID, error)
+}
+
+func (id BookID) bookID() (BookID, error) {
+	return id, id.Validate()
+}
+
+func (b *Book) bookID() (BookID, error) {
+	if b == nil {
+		return "", fmt.Errorf("%w: nil *Book", ErrInvalidID)
+	}
+	return b.ID.bookID()
+}
+
+func (id MovieID) movieID() (MovieID, error) {
+	return id, id.Validate()
+}
+
+func (m *Movie) movieID() (MovieID, error) {
+	if m == nil {
+		return "", fmt.Errorf("%w: nil *Movie", ErrInvalidID)
+	}
+	return m.ID.movieID()
+}
+
+func (id CharacterID) characterID() (CharacterID, error) {
+	return id, id.Validate()
+}
+
+func (c *Character) characterID() (CharacterID, error) {
+	if c == nil {
+		return "", fmt.Errorf("%w: nil *Character", ErrInvalidID)
+	}
+	return c.ID.characterID()
+}
+
+// resolveBook returns the ID of book, or an error if it is nil or invalid
+func resolveBook(book BookRef) (BookID, error) {
+	if book == nil {
+		return "", fmt.Errorf("%w: nil book", ErrInvalidID)
+	}
+	return book.bookID()
+}
+
+// resolveMovie returns the ID of movie, or an error if it is nil or invalid
+func resolveMovie(movie MovieRef) (MovieID, error) {
+	if movie == nil {
+		return "", fmt.Errorf("%w: nil movie", ErrInvalidID)
+	}
+	return movie.movieID()
+}
+
+// resolveCharacter returns the ID of character, or an error if it is nil or invalid
+func resolveCharacter(character CharacterRef) (CharacterID, error) {
+	if character == nil {
+		return "", fmt.Errorf("%w: nil character", ErrInvalidID)
+	}
+	return character.characterID()
+}
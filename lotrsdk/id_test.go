@@ -0,0 +1,65 @@
+package lotrsdk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseID(t *testing.T) {
+	id, err := ParseBookID("5cf58077b53e011a64671583")
+	assert.Nil(t, err)
+	assert.Equal(t, id, BookID("5cf58077b53e011a64671583"))
+
+	_, err = ParseMovieID("5CD95395DE30EFF6EBCCDE5B")
+	assert.Nil(t, err)
+
+	for _, invalid := range []string{"", "501", "5cd99d4bde30eff6ebccfe9", "5cd99d4bde30eff6ebccfe9e0", "5cd99d4bde30eff6ebccfe9g", "frodo-baggins-0123456789"} {
+		_, err := ParseCharacterID(invalid)
+		assert.True(t, errors.Is(err, ErrInvalidID), invalid)
+	}
+
+	assert.True(t, errors.Is(QuoteID("q").Validate(), ErrInvalidID))
+	assert.True(t, errors.Is(ChapterID("").Validate(), ErrInvalidID))
+	assert.Nil(t, ChapterID("6091b6d6d58360f988133b8b").Validate())
+}
+
+func TestSubResourceByID(t *testing.T) {
+	client, requests := newTestOneRingClient()
+	client.ChapterFromBook(BookID("5cf58077b53e011a64671583"))
+	client.QuoteFromMovie(MovieID("5cd95395de30eff6ebccde5b"))
+	client.QuoteFromCharacter(CharacterID("5cd99d4bde30eff6ebccfe9e"))
+	client.CountQuoteFromCharacter(CharacterID("5cd99d4bde30eff6ebccfe9e"))
+
+	paths := []string{"/book/5cf58077b53e011a64671583/chapter", "/movie/5cd95395de30eff6ebccde5b/quote",
+		"/character/5cd99d4bde30eff6ebccfe9e/quote", "/character/5cd99d4bde30eff6ebccfe9e/quote"}
+	assert.Equal(t, len(*requests), len(paths))
+	for i, path := range paths {
+		assert.Equal(t, (*requests)[i].URL.Path, path)
+	}
+}
+
+func TestSubResourceInvalidID(t *testing.T) {
+	client, requests := newTestOneRingClient()
+	var book *Book
+	var movie *Movie
+
+	_, _, err := client.ChapterFromBook(book)
+	assert.True(t, errors.Is(err, ErrInvalidID))
+	_, _, err = client.ChapterFromBook(nil)
+	assert.True(t, errors.Is(err, ErrInvalidID))
+	_, _, err = client.QuoteFromMovie(movie)
+	assert.True(t, errors.Is(err, ErrInvalidID))
+	_, _, err = client.QuoteFromCharacter(&Character{ID: "frodo"})
+	assert.True(t, errors.Is(err, ErrInvalidID))
+	_, err = client.CountChapterFromBook(BookID("47"))
+	assert.True(t, errors.Is(err, ErrInvalidID))
+	_, err = client.CountQuoteFromMovie(nil)
+	assert.True(t, errors.Is(err, ErrInvalidID))
+	_, err = client.CountQuoteFromCharacter(&Character{})
+	assert.True(t, errors.Is(err, ErrInvalidID))
+
+	// nothing is sent for an invalid ID
+	assert.Equal(t, len(*requests), 0)
+}
@@ -0,0 +1,91 @@
+package lotrsdk
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// windowLimiter allows at most n events in any sliding window of length interval
+// the zero value (or a nil pointer) never blocks
+type windowLimiter struct {
+	mu       sync.Mutex
+	n        int
+	interval time.Duration
+	// starts holds the times of the last n events, oldest first
+	starts []time.Time
+}
+
+// newWindowLimiter creates a windowLimiter; if either n or interval is not positive
+// nil is returned, which never blocks
+//   n - the number of events allowed per interval
+//   interval - the length of the sliding window
+func newWindowLimiter(n int, interval time.Duration) *windowLimiter {
+	if n <= 0 || interval <= 0 {
+		return nil
+	}
+	return &windowLimiter{
+		n:        n,
+		interval: interval,
+	}
+}
+
+// wait blocks until another event is allowed (or ctx is done) and records it
+func (wl *windowLimiter) wait(ctx context.Context) error {
+	if wl == nil {
+		return ctx.Err()
+	}
+
+	for {
+		delay := wl.reserve()
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve records an event if one is allowed right now, otherwise it returns how long
+// until the oldest event leaves the window
+func (wl *windowLimiter) reserve() time.Duration {
+	wl.mu.Lock()
+	defer wl.mu.Unlock()
+
+	now := time.Now()
+	if len(wl.starts) < wl.n {
+		wl.starts = append(wl.starts, now)
+		return 0
+	}
+
+	if delay := wl.starts[0].Add(wl.interval).Sub(now); delay > 0 {
+		return delay
+	}
+	wl.starts = append(wl.starts[1:], now)
+	return 0
+}
+
+// RateLimitMiddleware holds back requests so that at most requests are sent in any window
+// of length interval. Requests wait (in no particular order) until they can be sent, or until
+// their context is done. A single RateLimitMiddleware should be shared by every Client using
+// the same token.
+//   requests - the number of requests allowed per interval
+//   interval - the length of the window
+func RateLimitMiddleware(requests int, interval time.Duration) Middleware {
+	limiter := newWindowLimiter(requests, interval)
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if err := limiter.wait(req.Context()); err != nil {
+				return nil, err
+			}
+			return next(req)
+		}
+	}
+}
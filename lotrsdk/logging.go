@@ -0,0 +1,139 @@
+package lotrsdk
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	redacted = "REDACTED"
+)
+
+// secretKeyParts are the (lower case) substrings that mark a query param key as holding a secret
+var secretKeyParts = []string{"token", "key", "secret", "password", "passwd", "auth", "signature", "session", "credential"}
+
+// Logger is the interface the logging middleware writes to
+// it is a subset of *slog.Logger, so a *slog.Logger can be passed in directly; args are
+// alternating key-value pairs
+type Logger interface {
+	Debug(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// WithLogger adds LoggingMiddleware(logger) to the Client's request chain
+//   logger - where to write the logs
+func WithLogger(logger Logger) ClientOption {
+	return WithMiddleware(LoggingMiddleware(logger))
+}
+
+// LoggingMiddleware logs every request once it completes: successful requests at debug level,
+// and failed requests (network errors or statuses >= 400) at error level. Each entry has the
+// method, endpoint, raw query, status, latency, size of the body in bytes and the number of
+// retries; to see retries the logging middleware must come before RetryMiddleware in the chain.
+//
+// The Authorization header is never logged, and the values of any query params that look like
+// secrets (ie, token=..., api_key=...) are replaced with REDACTED.
+//   logger - where to write the logs
+func LoggingMiddleware(logger Logger) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			latency := time.Since(start)
+
+			endpoint := req.URL.Path
+			retries := 0
+			if state := getRequestState(req.Context()); state != nil {
+				endpoint = state.info.Endpoint
+				retries = state.retries
+			}
+			args := []any{
+				"method", req.Method,
+				"endpoint", endpoint,
+				"query", RedactQuery(req.URL.RawQuery),
+			}
+
+			if err != nil {
+				args = append(args, "latency", latency, "retries", retries, "error", redactError(err, req))
+				logger.Error("lotrsdk request failed", args...)
+				return nil, err
+			}
+
+			// buffer the body so we know its size; it is read in full by the Client anyway
+			body, readErr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+
+			args = append(args, "status", resp.StatusCode, "latency", latency, "bytes", len(body), "retries", retries)
+			if readErr != nil {
+				args = append(args, "error", redactError(readErr, req))
+				logger.Error("lotrsdk request failed", args...)
+				return nil, fmt.Errorf("failed to read response body: %w", readErr)
+			} else if resp.StatusCode >= 400 {
+				logger.Error("lotrsdk request failed", args...)
+			} else {
+				logger.Debug("lotrsdk request", args...)
+			}
+			return resp, nil
+		}
+	}
+}
+
+// RedactQuery returns rawQuery with the values of any params that look like secrets
+// (their key contains token, key, secret, password, auth, etc..) replaced with REDACTED
+//   rawQuery - the query in the form generated by a Filter
+func RedactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	parts := strings.Split(rawQuery, "&")
+	for i, part := range parts {
+		// keys end at the comparison operator (=, !=, <, >=, etc..)
+		opStart := strings.IndexAny(part, "=<>")
+		if opStart < 0 {
+			// ?key and ?!key have no value to hide
+			continue
+		}
+		if opStart > 0 && part[opStart-1] == '!' {
+			opStart--
+		}
+		opEnd := opStart
+		for opEnd < len(part) && strings.IndexByte("!=<>", part[opEnd]) >= 0 {
+			opEnd++
+		}
+
+		if isSecretKey(part[:opStart]) {
+			parts[i] = part[:opEnd] + redacted
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// isSecretKey reports whether a query param key looks like it holds a secret
+func isSecretKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, part := range secretKeyParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactError returns the error message with the request's query and bearer token redacted
+// (errors returned by net/http include the full URL)
+func redactError(err error, req *http.Request) string {
+	msg := err.Error()
+	if req.URL.RawQuery != "" {
+		msg = strings.ReplaceAll(msg, req.URL.RawQuery, RedactQuery(req.URL.RawQuery))
+	}
+	if token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "); token != "" {
+		msg = strings.ReplaceAll(msg, token, redacted)
+	}
+	return msg
+}
@@ -0,0 +1,113 @@
+package lotrsdk
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type logEntry struct {
+	level string
+	msg   string
+	attrs map[string]any
+}
+
+// testLogger records every entry so the tests can inspect them
+type testLogger struct {
+	entries []logEntry
+}
+
+func (tl *testLogger) log(level, msg string, args []any) {
+	attrs := map[string]any{}
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs[args[i].(string)] = args[i+1]
+	}
+	tl.entries = append(tl.entries, logEntry{level: level, msg: msg, attrs: attrs})
+}
+
+func (tl *testLogger) Debug(msg string, args ...any) { tl.log("debug", msg, args) }
+func (tl *testLogger) Error(msg string, args ...any) { tl.log("error", msg, args) }
+
+func TestLoggingMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(bookData))
+	}))
+	defer ts.Close()
+	logger := &testLogger{}
+	client := NewClient("secret-token", WithBaseURL(ts.URL), WithLogger(logger))
+
+	books, _, err := client.Books(Limit(2), BinaryFilter("api_key", FilterCompareEqual, "hunter2"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+	assert.Equal(t, len(logger.entries), 1)
+	entry := logger.entries[0]
+	assert.Equal(t, entry.level, "debug")
+	assert.Equal(t, entry.attrs["method"], "GET")
+	assert.Equal(t, entry.attrs["endpoint"], "/book")
+	assert.Equal(t, entry.attrs["query"], "limit=2&api_key=REDACTED")
+	assert.Equal(t, entry.attrs["status"], http.StatusOK)
+	assert.Equal(t, entry.attrs["bytes"], len(bookData))
+	assert.Equal(t, entry.attrs["retries"], 0)
+	assert.IsType(t, time.Duration(0), entry.attrs["latency"])
+	assert.NotContains(t, fmt.Sprint(entry.attrs), "secret-token")
+	assert.NotContains(t, fmt.Sprint(entry.attrs), "hunter2")
+}
+
+func TestLoggingMiddlewareFailure(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	logger := &testLogger{}
+	client := NewClient("secret-token", WithBaseURL(ts.URL),
+		WithLogger(logger), WithMiddleware(RetryMiddleware(RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond})))
+
+	_, _, err := client.Movies()
+
+	assert.NotNil(t, err)
+	assert.Equal(t, len(logger.entries), 1)
+	assert.Equal(t, logger.entries[0].level, "error")
+	assert.Equal(t, logger.entries[0].attrs["status"], http.StatusServiceUnavailable)
+	assert.Equal(t, logger.entries[0].attrs["retries"], 2)
+}
+
+func TestLoggingMiddlewareNetworkError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+	logger := &testLogger{}
+	client := NewClient("secret-token", WithBaseURL(ts.URL), WithLogger(logger))
+
+	_, _, err := client.Characters(BinaryFilter("password", FilterCompareEqual, "mellon"))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, len(logger.entries), 1)
+	assert.Equal(t, logger.entries[0].level, "error")
+	msg := logger.entries[0].attrs["error"].(string)
+	assert.True(t, strings.Contains(msg, "password=REDACTED"), msg)
+	assert.NotContains(t, msg, "mellon")
+}
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"", ""},
+		{"name=Gandalf&limit=5", "name=Gandalf&limit=5"},
+		{"token=abc123", "token=REDACTED"},
+		{"accessToken!=abc&name=Frodo", "accessToken!=REDACTED&name=Frodo"},
+		{"API_KEY>=7", "API_KEY>=REDACTED"},
+		{"secret&!password", "secret&!password"},
+		{"budgetInMillions<100", "budgetInMillions<100"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, RedactQuery(test.query), test.expected, test.query)
+	}
+}
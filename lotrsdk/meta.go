@@ -0,0 +1,97 @@
+package lotrsdk
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ResponseMeta holds the details of the HTTP response behind a call that are not part of
+// the JSON body (which is what Status holds); it is filled in by passing WithResponseMeta to a call
+type ResponseMeta struct {
+	// StatusCode is the HTTP status of the response (0 if no response was received)
+	StatusCode int
+	// Header holds all the response headers, including the caching headers (ETag, Cache-Control, etc..)
+	Header http.Header
+	// Date is the time the response was generated according to the server's Date header
+	Date time.Time
+	// RateLimit is the quota reported by the-one-api; HasRateLimit is false if the response
+	// did not include the rate limit headers
+	RateLimit    RateLimit
+	HasRateLimit bool
+	// Duration is how long the request took, including any retries
+	Duration time.Duration
+	// Retries is the number of retries RetryMiddleware needed
+	Retries int
+	// FromCache is true if the body was served from a cache instead of the network
+	FromCache bool
+	// Stale is true if the body was served from a cache past its max age, either while it is
+	// refreshed in the background or because the request failed
+	Stale bool
+}
+
+// RateLimit is the quota the-one-api reports with every response
+type RateLimit struct {
+	// Limit is the number of requests allowed per window
+	Limit int
+	// Remaining is the number of requests left in the current window
+	Remaining int
+	// Reset is when the current window ends (zero if unknown)
+	Reset time.Time
+}
+
+// WithResponseMeta fills in meta with the details of the response once the call completes
+// meta is filled in even if the call fails, with whatever is known
+//   meta - where to write the response details
+func WithResponseMeta(meta *ResponseMeta) Filter {
+	return callOption(func(cfg *callConfig) {
+		cfg.meta = meta
+	})
+}
+
+// fillResponseMeta writes the details of resp (which may be nil) into meta
+func fillResponseMeta(meta *ResponseMeta, resp *http.Response, state *requestState, duration time.Duration) {
+	*meta = ResponseMeta{
+		Duration:  duration,
+		Retries:   state.retries,
+		FromCache: state.fromCache,
+		Stale:     state.stale,
+	}
+	if resp == nil {
+		return
+	}
+
+	meta.StatusCode = resp.StatusCode
+	meta.Header = resp.Header.Clone()
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		meta.Date = date
+	}
+	meta.RateLimit, meta.HasRateLimit = parseRateLimit(resp.Header)
+}
+
+// parseRateLimit reads the X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers
+// the second return is false if the response did not have them
+func parseRateLimit(header http.Header) (RateLimit, bool) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+
+	rl := RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+	}
+	// the reset is either a unix timestamp or a number of seconds from now
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		if reset > 1e9 {
+			rl.Reset = time.Unix(reset, 0)
+		} else {
+			rl.Reset = time.Now().Add(time.Duration(reset) * time.Second)
+		}
+	}
+	return rl, true
+}
@@ -0,0 +1,68 @@
+package lotrsdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseMeta(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("X-RateLimit-Reset", "1670000000")
+		w.Header().Set("ETag", `W/"abc"`)
+		w.Write([]byte(bookData))
+	}))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+	var meta ResponseMeta
+	books, _, err := client.Books(WithResponseMeta(&meta))
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+	assert.Equal(t, meta.StatusCode, http.StatusOK)
+	assert.True(t, meta.HasRateLimit)
+	assert.Equal(t, meta.RateLimit.Limit, 100)
+	assert.Equal(t, meta.RateLimit.Remaining, 42)
+	assert.Equal(t, meta.RateLimit.Reset, time.Unix(1670000000, 0))
+	assert.Equal(t, meta.Header.Get("ETag"), `W/"abc"`)
+	assert.WithinDuration(t, meta.Date, time.Now(), 5*time.Second)
+	assert.Greater(t, meta.Duration, time.Duration(0))
+	assert.False(t, meta.FromCache)
+}
+
+func TestResponseMetaOnFailure(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL),
+		WithMiddleware(RetryMiddleware(RetryPolicy{MaxRetries: 1})))
+
+	var meta ResponseMeta
+	_, err := client.CountQuotes(WithResponseMeta(&meta))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, meta.StatusCode, http.StatusTooManyRequests)
+	assert.Equal(t, meta.Retries, 1)
+	assert.False(t, meta.HasRateLimit)
+}
+
+func TestParseRateLimitRelativeReset(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "100")
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", "600")
+
+	rl, ok := parseRateLimit(header)
+
+	assert.True(t, ok)
+	assert.Equal(t, rl.Remaining, 0)
+	assert.WithinDuration(t, rl.Reset, time.Now().Add(10*time.Minute), 5*time.Second)
+}
@@ -0,0 +1,248 @@
+package lotrsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds (in seconds) of the request latency histogram
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics collects counters and histograms about the requests made by a Client
+// and serves them in the Prometheus text exposition format (it implements http.Handler).
+// A single Metrics can be shared by several Clients.
+type Metrics struct {
+	mu sync.Mutex
+
+	// requests counts requests by resource and status class
+	requests map[metricLabels]uint64
+	// latency holds a latency histogram per resource
+	latency map[string]*histogram
+	// retries counts retries per resource (needs the metrics middleware before RetryMiddleware)
+	retries map[string]uint64
+	// cacheRequests counts requests looked up in a Cache by resource and result (hit or miss)
+	// (needs the metrics middleware before the cache middleware)
+	cacheRequests map[metricLabels]uint64
+
+	// the quota reported by the last response that had rate limit headers
+	rateLimit    RateLimit
+	hasRateLimit bool
+}
+
+// metricLabels is the resource plus a second label: the status class of a request,
+// or the result of a cache lookup
+type metricLabels struct {
+	resource    string
+	statusClass string
+}
+
+type histogram struct {
+	// counts[i] is the number of observations <= latencyBuckets[i]; these are not cumulative
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (h *histogram) observe(value float64) {
+	h.count++
+	h.sum += value
+	for i, bound := range latencyBuckets {
+		if value <= bound {
+			h.counts[i]++
+			return
+		}
+	}
+}
+
+// NewMetrics creates an empty Metrics
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests: map[metricLabels]uint64{},
+		latency:  map[string]*histogram{},
+		retries:  map[string]uint64{},
+
+		cacheRequests: map[metricLabels]uint64{},
+	}
+}
+
+// WithMetrics adds metrics.Middleware() to the Client's request chain
+//   metrics - where to record the requests
+func WithMetrics(metrics *Metrics) ClientOption {
+	return WithMiddleware(metrics.Middleware())
+}
+
+// Middleware returns the Middleware that records every request into m
+// to count retries it must come before RetryMiddleware in the chain
+func (m *Metrics) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+			m.record(req, resp, err, time.Since(start))
+			return resp, err
+		}
+	}
+}
+
+func (m *Metrics) record(req *http.Request, resp *http.Response, err error, latency time.Duration) {
+	resource := resourceFromEndpoint(req.URL.Path)
+	retries := 0
+	cacheResult := ""
+	if state := getRequestState(req.Context()); state != nil {
+		resource = state.info.Resource
+		retries = state.retries
+		if state.fromCache {
+			cacheResult = "hit"
+		} else if state.cacheLookup {
+			cacheResult = "miss"
+		}
+	}
+
+	var rateLimit RateLimit
+	hasRateLimit := false
+	if resp != nil {
+		rateLimit, hasRateLimit = parseRateLimit(resp.Header)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[metricLabels{resource: resource, statusClass: statusClass(resp, err)}]++
+	m.retries[resource] += uint64(retries)
+	if cacheResult != "" {
+		m.cacheRequests[metricLabels{resource: resource, statusClass: cacheResult}]++
+	}
+	h, ok := m.latency[resource]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(latencyBuckets))}
+		m.latency[resource] = h
+	}
+	h.observe(latency.Seconds())
+	if hasRateLimit {
+		m.rateLimit = rateLimit
+		m.hasRateLimit = true
+	}
+}
+
+// statusClass buckets the outcome of a request: 2xx, 3xx, 4xx and 5xx for responses,
+// and timeout, canceled or network_error for requests that got no response
+func statusClass(resp *http.Response, err error) string {
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case errors.Is(err, context.Canceled):
+			return "canceled"
+		case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+			return "timeout"
+		}
+		return "network_error"
+	}
+	return fmt.Sprintf("%dxx", resp.StatusCode/100)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+//   w - where to write the metrics
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sb strings.Builder
+
+	sb.WriteString("# HELP lotrsdk_requests_total Requests made to the-one-api by resource and status class.\n")
+	sb.WriteString("# TYPE lotrsdk_requests_total counter\n")
+	for _, labels := range sortedLabels(m.requests) {
+		fmt.Fprintf(&sb, "lotrsdk_requests_total{resource=%s,status_class=%s} %d\n",
+			quoteLabel(labels.resource), quoteLabel(labels.statusClass), m.requests[labels])
+	}
+
+	sb.WriteString("# HELP lotrsdk_request_duration_seconds Latency of requests to the-one-api by resource.\n")
+	sb.WriteString("# TYPE lotrsdk_request_duration_seconds histogram\n")
+	for _, resource := range sortedKeys(m.latency) {
+		h := m.latency[resource]
+		var cumulative uint64
+		for i, bound := range latencyBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&sb, "lotrsdk_request_duration_seconds_bucket{resource=%s,le=\"%s\"} %d\n",
+				quoteLabel(resource), strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&sb, "lotrsdk_request_duration_seconds_bucket{resource=%s,le=\"+Inf\"} %d\n", quoteLabel(resource), h.count)
+		fmt.Fprintf(&sb, "lotrsdk_request_duration_seconds_sum{resource=%s} %s\n", quoteLabel(resource), strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&sb, "lotrsdk_request_duration_seconds_count{resource=%s} %d\n", quoteLabel(resource), h.count)
+	}
+
+	sb.WriteString("# HELP lotrsdk_retries_total Retries of requests to the-one-api by resource.\n")
+	sb.WriteString("# TYPE lotrsdk_retries_total counter\n")
+	for _, resource := range sortedKeys(m.retries) {
+		fmt.Fprintf(&sb, "lotrsdk_retries_total{resource=%s} %d\n", quoteLabel(resource), m.retries[resource])
+	}
+
+	sb.WriteString("# HELP lotrsdk_cache_requests_total Requests looked up in a Cache by resource and result (hit or miss).\n")
+	sb.WriteString("# TYPE lotrsdk_cache_requests_total counter\n")
+	for _, labels := range sortedLabels(m.cacheRequests) {
+		fmt.Fprintf(&sb, "lotrsdk_cache_requests_total{resource=%s,result=%s} %d\n",
+			quoteLabel(labels.resource), quoteLabel(labels.statusClass), m.cacheRequests[labels])
+	}
+
+	if m.hasRateLimit {
+		sb.WriteString("# HELP lotrsdk_rate_limit_limit Requests allowed per rate limit window, as last reported by the-one-api.\n")
+		sb.WriteString("# TYPE lotrsdk_rate_limit_limit gauge\n")
+		fmt.Fprintf(&sb, "lotrsdk_rate_limit_limit %d\n", m.rateLimit.Limit)
+		sb.WriteString("# HELP lotrsdk_rate_limit_remaining Requests remaining in the rate limit window, as last reported by the-one-api.\n")
+		sb.WriteString("# TYPE lotrsdk_rate_limit_remaining gauge\n")
+		fmt.Fprintf(&sb, "lotrsdk_rate_limit_remaining %d\n", m.rateLimit.Remaining)
+		if !m.rateLimit.Reset.IsZero() {
+			sb.WriteString("# HELP lotrsdk_rate_limit_reset_timestamp_seconds When the rate limit window resets, as a unix timestamp.\n")
+			sb.WriteString("# TYPE lotrsdk_rate_limit_reset_timestamp_seconds gauge\n")
+			fmt.Fprintf(&sb, "lotrsdk_rate_limit_reset_timestamp_seconds %d\n", m.rateLimit.Reset.Unix())
+		}
+	}
+
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// quoteLabel quotes a label value, escaping it as required by the exposition format
+func quoteLabel(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+func sortedLabels(m map[metricLabels]uint64) []metricLabels {
+	labels := make([]metricLabels, 0, len(m))
+	for l := range m {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].resource != labels[j].resource {
+			return labels[i].resource < labels[j].resource
+		}
+		return labels[i].statusClass < labels[j].statusClass
+	})
+	return labels
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+package lotrsdk
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "97")
+		w.Header().Set("X-RateLimit-Reset", "1670000000")
+		if r.URL.Path == "/movie" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(bookData))
+	}))
+	defer ts.Close()
+	metrics := NewMetrics()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithMetrics(metrics))
+
+	client.Books()
+	client.Books()
+	client.Movies()
+	client.ChapterFromBook(&Book{ID: "5cf58077b53e011a64671583"})
+
+	server := httptest.NewServer(metrics)
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	text := string(body)
+
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain"))
+	assert.Contains(t, text, "# TYPE lotrsdk_requests_total counter\n")
+	assert.Contains(t, text, `lotrsdk_requests_total{resource="book",status_class="2xx"} 2`)
+	assert.Contains(t, text, `lotrsdk_requests_total{resource="movie",status_class="4xx"} 1`)
+	assert.Contains(t, text, `lotrsdk_requests_total{resource="chapter",status_class="2xx"} 1`)
+	assert.Contains(t, text, "# TYPE lotrsdk_request_duration_seconds histogram\n")
+	assert.Contains(t, text, `lotrsdk_request_duration_seconds_bucket{resource="book",le="+Inf"} 2`)
+	assert.Contains(t, text, `lotrsdk_request_duration_seconds_count{resource="book"} 2`)
+	assert.Contains(t, text, "lotrsdk_rate_limit_limit 100\n")
+	assert.Contains(t, text, "lotrsdk_rate_limit_remaining 97\n")
+	assert.Contains(t, text, "lotrsdk_rate_limit_reset_timestamp_seconds 1670000000\n")
+}
+
+func TestMetricsNetworkError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+	metrics := NewMetrics()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithMetrics(metrics))
+
+	client.Quotes()
+
+	var sb strings.Builder
+	metrics.WriteTo(&sb)
+	assert.Contains(t, sb.String(), `lotrsdk_requests_total{resource="quote",status_class="network_error"} 1`)
+	assert.NotContains(t, sb.String(), "lotrsdk_rate_limit_remaining")
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	h := &histogram{counts: make([]uint64, len(latencyBuckets))}
+	h.observe(0.01)
+	h.observe(0.3)
+	h.observe(60)
+
+	assert.Equal(t, h.count, uint64(3))
+	assert.Equal(t, h.counts[0], uint64(1))
+	assert.Equal(t, h.counts[3], uint64(1))
+	assert.InDelta(t, h.sum, 60.31, 1e-9)
+}
@@ -0,0 +1,89 @@
+package lotrsdk
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// RoundTripFunc sends a single request and returns its response
+// it has the same contract as http.RoundTripper: a non-nil error means there is no response,
+// and otherwise the caller is responsible for closing the response body
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps the sending of a request; it can inspect or modify the request before
+// calling next, and the response (or error) after. A Middleware may also skip calling next
+// entirely (ie, to answer from a cache), or call it more than once (ie, to retry).
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// WithMiddleware adds middlewares to the Client's request chain
+// middlewares run in the order they are added: the first one added sees the request first
+// and the response last. The option can be used several times; each use appends to the chain.
+//   middlewares - the middlewares to add
+func WithMiddleware(middlewares ...Middleware) ClientOption {
+	return func(c *client) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// chainMiddlewares wraps final in every middleware so that middlewares[0] is the outermost
+func chainMiddlewares(middlewares []Middleware, final RoundTripFunc) RoundTripFunc {
+	rt := final
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = middlewares[i](rt)
+	}
+	return rt
+}
+
+// RequestInfo describes the Client call a request was made for
+type RequestInfo struct {
+	// Resource is the kind of record being requested: book, movie, character, quote or chapter
+	Resource string
+	// Endpoint is the path requested, relative to the API URL (ie, /book/{id}/chapter)
+	Endpoint string
+	// Filter holds all the filters passed to the call
+	Filter Filter
+}
+
+// GetRequestInfo returns the RequestInfo attached to a request sent by a Client
+// the second return is false if req was not sent by a Client
+//   req - the request passed to a Middleware
+func GetRequestInfo(req *http.Request) (RequestInfo, bool) {
+	state := getRequestState(req.Context())
+	if state == nil {
+		return RequestInfo{}, false
+	}
+	return state.info, true
+}
+
+// requestState is shared by every middleware handling a single call; it lets the built-in
+// middlewares report what they did (ie, how many retries were needed) to the ones around them
+type requestState struct {
+	info     RequestInfo
+	priority Priority
+	retries  int
+	// cacheLookup is set by middlewares that looked the request up in a cache, and fromCache
+	// by those that answered it from the cache instead of the network; stale is set when that
+	// answer was past its max age
+	cacheLookup bool
+	fromCache   bool
+	stale       bool
+}
+
+type requestStateKey struct{}
+
+func withRequestState(ctx context.Context, state *requestState) context.Context {
+	return context.WithValue(ctx, requestStateKey{}, state)
+}
+
+// getRequestState returns the requestState for the call, or nil if there is none
+func getRequestState(ctx context.Context) *requestState {
+	state, _ := ctx.Value(requestStateKey{}).(*requestState)
+	return state
+}
+
+// resourceFromEndpoint returns the kind of record an endpoint returns,
+// which is always the last segment of its path (/book -> book, /book/{id}/chapter -> chapter)
+func resourceFromEndpoint(endpoint string) string {
+	return endpoint[strings.LastIndexByte(endpoint, '/')+1:]
+}
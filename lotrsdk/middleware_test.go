@@ -0,0 +1,214 @@
+package lotrsdk
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	var order []string
+	named := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name+" request")
+				resp, err := next(req)
+				order = append(order, name+" response")
+				return resp, err
+			}
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(bookData))
+	}))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithMiddleware(named("a"), named("b")), WithMiddleware(named("c")))
+
+	_, _, err := client.Books()
+
+	assert.Nil(t, err)
+	assert.Equal(t, order, []string{"a request", "b request", "c request", "c response", "b response", "a response"})
+}
+
+func TestMiddlewareRequestInfo(t *testing.T) {
+	var info RequestInfo
+	var header string
+	inspect := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			info, _ = GetRequestInfo(req)
+			req.Header.Set("X-Request-Source", "middleware")
+			return next(req)
+		}
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Request-Source")
+	}))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithMiddleware(inspect))
+
+	client.QuoteFromMovie(&Movie{ID: "5cd95395de30eff6ebccde5b"}, Limit(5))
+
+	assert.Equal(t, info.Resource, "quote")
+	assert.Equal(t, info.Endpoint, "/movie/5cd95395de30eff6ebccde5b/quote")
+	query, err := info.Filter.GenerateRawQuery()
+	assert.Nil(t, err)
+	assert.Equal(t, query, "limit=5")
+	assert.Equal(t, header, "middleware")
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	var sent int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&sent, 1)
+	}))
+	defer ts.Close()
+	fake := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(bytes.NewReader([]byte(bookData))),
+				Request:    req,
+			}, nil
+		}
+	}
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithMiddleware(fake))
+
+	books, _, err := client.Books()
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+	assert.Equal(t, atomic.LoadInt64(&sent), int64(0))
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	var attempts int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt64(&attempts, 1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(bookData))
+		}
+	}))
+	defer ts.Close()
+
+	var retries int
+	recordRetries := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			retries = getRequestState(req.Context()).retries
+			return resp, err
+		}
+	}
+	client := NewClient("fake-token", WithBaseURL(ts.URL),
+		WithMiddleware(recordRetries, RetryMiddleware(RetryPolicy{MinBackoff: time.Millisecond})))
+
+	books, _, err := client.Books()
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+	assert.Equal(t, atomic.LoadInt64(&attempts), int64(3))
+	assert.Equal(t, retries, 2)
+}
+
+func TestRetryMiddlewareGivesUp(t *testing.T) {
+	var attempts int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&attempts, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL),
+		WithMiddleware(RetryMiddleware(RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond})))
+
+	_, _, err := client.Books()
+
+	assert.NotNil(t, err)
+	assert.Equal(t, atomic.LoadInt64(&attempts), int64(3))
+}
+
+func TestRetryMiddlewareNoRetries(t *testing.T) {
+	var attempts int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&attempts, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL),
+		WithMiddleware(RetryMiddleware(RetryPolicy{MaxRetries: NoRetries, MinBackoff: time.Millisecond})))
+
+	_, _, err := client.Books()
+
+	assert.NotNil(t, err)
+	assert.Equal(t, atomic.LoadInt64(&attempts), int64(1))
+}
+
+func TestRetryBackoffCapped(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: time.Second, MaxBackoff: 4 * time.Second}
+	for attempt := 0; attempt < 10; attempt++ {
+		for i := 0; i < 50; i++ {
+			delay := policy.backoff(attempt, nil)
+			assert.LessOrEqual(t, delay, policy.MaxBackoff)
+			assert.GreaterOrEqual(t, delay, time.Second)
+		}
+	}
+}
+
+func TestRetryMiddlewareSkipsClientErrors(t *testing.T) {
+	var attempts int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&attempts, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL),
+		WithMiddleware(RetryMiddleware(RetryPolicy{MinBackoff: time.Millisecond})))
+
+	_, _, err := client.Books()
+
+	assert.NotNil(t, err)
+	assert.Equal(t, atomic.LoadInt64(&attempts), int64(1))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	delay, ok := parseRetryAfter("120")
+	assert.True(t, ok)
+	assert.Equal(t, delay, 2*time.Minute)
+
+	delay, ok = parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, float64(time.Hour), float64(delay), float64(2*time.Second))
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(bookData))
+	}))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL),
+		WithMiddleware(RateLimitMiddleware(2, 50*time.Millisecond)))
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, _, err := client.Books()
+		assert.Nil(t, err)
+	}
+
+	// 5 requests at 2 per 50ms needs at least two full windows
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
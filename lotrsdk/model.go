@@ -1,6 +1,7 @@
 package lotrsdk
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 )
@@ -8,46 +9,81 @@ import (
 // this file contains the structs representing the JSON response we get from the API
 
 type Book struct {
-	ID   string `json:"_id"`
+	ID   BookID `json:"_id"`
 	Name string `json:"name"`
 }
 
+// Movie numbers the-one-api may leave unknown are Optionals; they may be sent as numbers or strings,
+// and unknown values ("", "NaN", null) or missing fields are not Valid
 type Movie struct {
-	ID                         string  `json:"_id"`
-	Name                       string  `json:"name"`
-	RuntimeInMinutes           int     `json:"runtimeInMinutes"`
-	BudgetInMillions           float32 `json:"budgetInMillions"`
-	BoxOfficeRevenueInMillions float32 `json:"boxOfficeRevenueInMillions"`
-	AcademyAwardNominations    int     `json:"academyAwardNominations"`
-	AcademyAwardWins           int     `json:"academyAwardWins"`
-	RottenTomatoesScore        float32 `json:"rottenTomatoesScore"`
+	ID                         MovieID           `json:"_id"`
+	Name                       string            `json:"name"`
+	RuntimeInMinutes           Optional[int]     `json:"runtimeInMinutes"`
+	BudgetInMillions           Optional[float64] `json:"budgetInMillions"`
+	BoxOfficeRevenueInMillions Optional[float64] `json:"boxOfficeRevenueInMillions"`
+	AcademyAwardNominations    Optional[int]     `json:"academyAwardNominations"`
+	AcademyAwardWins           Optional[int]     `json:"academyAwardWins"`
+	RottenTomatoesScore        Optional[float64] `json:"rottenTomatoesScore"`
 }
 
 type Character struct {
-	ID      string `json:"_id"`
-	Birth   string `json:"birth"`
-	Death   string `json:"death"`
-	Hair    string `json:"hair"`
-	Realm   string `json:"realm"`
-	Height  string `json:"height"`
-	Spouse  string `json:"spouse"`
-	Gender  string `json:"gender"`
-	Name    string `json:"name"`
-	Race    string `json:"race"`
-	WikiURL string `json:"wikiUrl"`
+	ID      CharacterID `json:"_id"`
+	Birth   string      `json:"birth"`
+	Death   string      `json:"death"`
+	Hair    string      `json:"hair"`
+	Realm   string      `json:"realm"`
+	Height  string      `json:"height"`
+	Spouse  string      `json:"spouse"`
+	Gender  string      `json:"gender"`
+	Name    string      `json:"name"`
+	Race    string      `json:"race"`
+	WikiURL string      `json:"wikiUrl"`
+}
+
+// NormalizedCharacter is a Character with the values the-one-api uses to mean "unknown" ("", "NaN")
+// turned into unknown Optionals, so they are not mistaken for real values
+type NormalizedCharacter struct {
+	ID      CharacterID
+	Name    string
+	Birth   Optional[string]
+	Death   Optional[string]
+	Hair    Optional[string]
+	Realm   Optional[string]
+	Height  Optional[string]
+	Spouse  Optional[string]
+	Gender  Optional[string]
+	Race    Optional[string]
+	WikiURL Optional[string]
+}
+
+// Normalize returns the character with unknown values told apart from known ones
+func (c Character) Normalize() NormalizedCharacter {
+	return NormalizedCharacter{
+		ID:      c.ID,
+		Name:    c.Name,
+		Birth:   optionalString(c.Birth),
+		Death:   optionalString(c.Death),
+		Hair:    optionalString(c.Hair),
+		Realm:   optionalString(c.Realm),
+		Height:  optionalString(c.Height),
+		Spouse:  optionalString(c.Spouse),
+		Gender:  optionalString(c.Gender),
+		Race:    optionalString(c.Race),
+		WikiURL: optionalString(c.WikiURL),
+	}
 }
 
 type Quote struct {
-	ID        string `json:"_id"`
-	Dialog    string `json:"dialog"`
-	Movie     string `json:"movie"`
-	Character string `json:"character"`
+	ID        QuoteID     `json:"_id"`
+	Dialog    string      `json:"dialog"`
+	Movie     MovieID     `json:"movie"`
+	Character CharacterID `json:"character"`
 }
 
 type Chapter struct {
-	ID          string `json:"_id"`
-	ChapterName string `json:"chapterName"`
-	Book        string `json:"book"`
+	ID          ChapterID `json:"_id"`
+	ChapterName string    `json:"chapterName"`
+	Book        BookID    `json:"book"`
 }
 
 // Status is kept separate from the rest of the structs as a user "probably" doesn't want to deal
@@ -61,20 +97,41 @@ type Status struct {
 }
 
 // struct to assist in unmarshalling
-type unmarshalStruct[T any] struct {
-	Docs []T `json:"docs"`
+// Docs is kept raw so a missing docs field can be told apart from an empty one
+type unmarshalStruct struct {
+	Docs json.RawMessage `json:"docs"`
 	Status
 }
 
 // unmarshalJSON is a helper function that reads in a byte slice and puts the data into the approriate struct
+// the errors it returns are *DecodeError
 //   T - the type we are reading
 //   b - the byte array from which to read
 func unmarshalJSON[T any](b []byte) ([]T, Status, error) {
-	data := unmarshalStruct[T]{}
+	trimmed := bytes.TrimSpace(b)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '"') {
+			return nil, Status{}, &DecodeError{Snippet: snippet(b), Err: fmt.Errorf("%w: expected an object", ErrInvalidJSON)}
+		}
+		return nil, Status{}, &DecodeError{Snippet: snippet(b), Err: ErrNotJSON}
+	}
+
+	data := unmarshalStruct{}
 	err := json.Unmarshal(b, &data)
 	if err != nil {
-		return nil, Status{}, fmt.Errorf("failed to unmarshal bytes: %w", err)
+		return nil, Status{}, &DecodeError{Snippet: snippet(b), Err: &invalidJSONError{err: err}}
+	}
+	if message, ok := envelopeMessage(b); ok {
+		return nil, Status{}, &DecodeError{Message: message, Snippet: snippet(b), Err: ErrAPIError}
+	}
+	if len(data.Docs) == 0 || string(data.Docs) == "null" {
+		return nil, Status{}, &DecodeError{Snippet: snippet(b), Err: ErrMissingDocs}
+	}
+
+	docs := []T{}
+	if err := json.Unmarshal(data.Docs, &docs); err != nil {
+		return nil, Status{}, &DecodeError{Snippet: snippet(b), Err: &invalidJSONError{err: err}}
 	}
 
-	return data.Docs, data.Status, nil
+	return docs, data.Status, nil
 }
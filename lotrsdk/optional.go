@@ -0,0 +1,117 @@
+package lotrsdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Optional is a value the-one-api may leave unknown; it tells an unknown value apart from a known one,
+// even a known empty one. When decoding, null, "", "NaN" and a missing field are all unknown, and numeric
+// types also accept numbers sent as strings.
+type Optional[T any] struct {
+	Value T
+	// Valid is false if the value is unknown
+	Valid bool
+}
+
+// Some returns a known Optional holding value
+//   value - the value held
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{Value: value, Valid: true}
+}
+
+// Get returns the value and whether it is known
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Valid
+}
+
+// OrElse returns the value if it is known, and fallback otherwise
+//   fallback - the value returned if the value is unknown
+func (o Optional[T]) OrElse(fallback T) T {
+	if o.Valid {
+		return o.Value
+	}
+	return fallback
+}
+
+func (o Optional[T]) String() string {
+	if !o.Valid {
+		return "unknown"
+	}
+	return fmt.Sprint(o.Value)
+}
+
+// MarshalJSON writes an unknown value as null
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	*o = Optional[T]{}
+	if isUnknownJSON(b) {
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(b, &value); err != nil {
+		// numbers are sometimes sent as strings
+		if !isNumericKind(reflect.TypeOf(value)) || b[0] != '"' {
+			return err
+		}
+		var s string
+		if json.Unmarshal(b, &s) != nil {
+			return err
+		}
+		if json.Unmarshal([]byte(strings.TrimSpace(s)), &value) != nil {
+			return err
+		}
+	}
+	o.Value = value
+	o.Valid = true
+	return nil
+}
+
+// IsUnknown reports whether s is one of the values the-one-api uses to mean "unknown" ("", "NaN")
+//   s - a raw field value
+func IsUnknown(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || strings.EqualFold(s, "NaN")
+}
+
+// optionalString returns s as an Optional, unknown if IsUnknown(s)
+func optionalString(s string) Optional[string] {
+	if IsUnknown(s) {
+		return Optional[string]{}
+	}
+	return Some(strings.TrimSpace(s))
+}
+
+// isUnknownJSON reports whether b is null or a string that IsUnknown
+func isUnknownJSON(b []byte) bool {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || bytes.Equal(b, []byte("null")) {
+		return true
+	}
+	var s string
+	return b[0] == '"' && json.Unmarshal(b, &s) == nil && IsUnknown(s)
+}
+
+func isNumericKind(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package lotrsdk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionalUnmarshal(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected Optional[string]
+	}{
+		{`"Belemir"`, Some("Belemir")},
+		{`""`, Optional[string]{}},
+		{`"NaN"`, Optional[string]{}},
+		{`" nan "`, Optional[string]{}},
+		{`null`, Optional[string]{}},
+	}
+	for _, test := range tests {
+		var o Optional[string]
+		assert.Nil(t, json.Unmarshal([]byte(test.data), &o), test.data)
+		assert.Equal(t, o, test.expected, test.data)
+	}
+
+	var missing struct {
+		Spouse Optional[string] `json:"spouse"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{}`), &missing))
+	assert.False(t, missing.Spouse.Valid)
+}
+
+func TestOptionalNumbers(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected Optional[float64]
+	}{
+		{`2917`, Some(2917.0)},
+		{`"2917.5"`, Some(2917.5)},
+		{`" 94 "`, Some(94.0)},
+		{`"NaN"`, Optional[float64]{}},
+		{`""`, Optional[float64]{}},
+	}
+	for _, test := range tests {
+		var o Optional[float64]
+		assert.Nil(t, json.Unmarshal([]byte(test.data), &o), test.data)
+		assert.Equal(t, o, test.expected, test.data)
+	}
+
+	var o Optional[int]
+	assert.NotNil(t, json.Unmarshal([]byte(`"tall"`), &o))
+	assert.NotNil(t, json.Unmarshal([]byte(`true`), &o))
+}
+
+func TestOptionalAccessors(t *testing.T) {
+	known := Some("Rohan")
+	value, ok := known.Get()
+	assert.True(t, ok)
+	assert.Equal(t, value, "Rohan")
+	assert.Equal(t, known.OrElse("?"), "Rohan")
+	assert.Equal(t, known.String(), "Rohan")
+
+	unknown := Optional[string]{}
+	assert.Equal(t, unknown.OrElse("?"), "?")
+	assert.Equal(t, unknown.String(), "unknown")
+
+	b, err := json.Marshal([]Optional[string]{known, unknown})
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), `["Rohan",null]`)
+}
+
+func TestUnmarshalLenientMovie(t *testing.T) {
+	data := `{"docs":[{"_id":"5cd95395de30eff6ebccde56","name":"The Lord of the Rings Series","runtimeInMinutes":"558","budgetInMillions":281,"boxOfficeRevenueInMillions":"2917.3","academyAwardNominations":30,"academyAwardWins":"NaN","rottenTomatoesScore":""}],"total":1,"limit":1,"offset":0,"page":1,"pages":1}`
+	client := newTestClientWithMockServer(data)
+	movies, _, err := client.Movies()
+
+	assert.Nil(t, err)
+	assert.Equal(t, movies[0].Name, "The Lord of the Rings Series")
+	assert.Equal(t, movies[0].RuntimeInMinutes, Some(558))
+	assert.Equal(t, movies[0].BudgetInMillions, Some(281.0))
+	assert.Equal(t, movies[0].BoxOfficeRevenueInMillions, Some(2917.3))
+	assert.Equal(t, movies[0].AcademyAwardNominations, Some(30))
+	assert.Equal(t, movies[0].AcademyAwardWins, Optional[int]{})
+	assert.Equal(t, movies[0].RottenTomatoesScore, Optional[float64]{})
+
+	// unknown values are written as null, so a movie can be encoded again
+	b, err := json.Marshal(movies[0])
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `"academyAwardWins":null,"rottenTomatoesScore":null`)
+
+	client = newTestClientWithMockServer(`{"docs":[{"_id":"1","budgetInMillions":"lots"}]}`)
+	_, _, err = client.Movies()
+	assert.ErrorIs(t, err, ErrInvalidJSON)
+}
+
+func TestNormalizeCharacter(t *testing.T) {
+	character := Character{ID: "5cd99d4bde30eff6ebccfbbe", Name: "Adanel", Spouse: "NaN", Race: "Human", Height: "", Realm: " Rohan "}
+	normalized := character.Normalize()
+
+	assert.Equal(t, normalized.Name, "Adanel")
+	assert.False(t, normalized.Spouse.Valid)
+	assert.False(t, normalized.Height.Valid)
+	assert.Equal(t, normalized.Race, Some("Human"))
+	assert.Equal(t, normalized.Realm, Some("Rohan"))
+	assert.True(t, IsUnknown("nan"))
+	assert.False(t, IsUnknown("Nandor"))
+}
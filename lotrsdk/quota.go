@@ -0,0 +1,230 @@
+package lotrsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// the-one-api allows 100 requests every 10 minutes per token
+	defaultQuotaLimit  = 100
+	defaultQuotaWindow = 10 * time.Minute
+)
+
+// QuotaLedger keeps track of the requests made with a token in a file, so that several processes
+// on the same host sharing the token also share its quota. Every access to the file is done under
+// an exclusive file lock.
+//
+// A QuotaLedger is safe for concurrent use, and any number of QuotaLedgers (in any number of
+// processes) may use the same file, as long as they are all created with the same limit and window.
+type QuotaLedger struct {
+	path   string
+	limit  int
+	window time.Duration
+
+	// mu serializes access from this process; the file lock handles the other processes
+	mu sync.Mutex
+}
+
+// ledgerFile is the content of the ledger file
+type ledgerFile struct {
+	// Requests holds the times (unix nanoseconds) of the requests made in the current window, oldest first
+	Requests []int64 `json:"requests"`
+	// BlockedUntil (unix nanoseconds) is set when the-one-api reports that the quota is exhausted
+	BlockedUntil int64 `json:"blockedUntil,omitempty"`
+}
+
+// NewQuotaLedger creates a QuotaLedger backed by the file at path, creating the file if needed
+//   path - the file shared by every process using the token
+//   limit - the number of requests allowed per window; defaults to 100
+//   window - the length of the window; defaults to 10 minutes
+func NewQuotaLedger(path string, limit int, window time.Duration) (*QuotaLedger, error) {
+	if limit <= 0 {
+		limit = defaultQuotaLimit
+	}
+	if window <= 0 {
+		window = defaultQuotaWindow
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open quota ledger: %w", err)
+	}
+	f.Close()
+
+	return &QuotaLedger{
+		path:   path,
+		limit:  limit,
+		window: window,
+	}, nil
+}
+
+// WithQuotaLedger adds ledger.Middleware() to the Client's request chain
+//   ledger - the ledger shared with the other processes
+func WithQuotaLedger(ledger *QuotaLedger) ClientOption {
+	return WithMiddleware(ledger.Middleware())
+}
+
+// Middleware returns a Middleware that waits for a free slot in the ledger before sending each request
+// and records when the-one-api reports that the quota is exhausted
+// it should come after RetryMiddleware in the chain, so that every retry also takes a slot
+func (ql *QuotaLedger) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			if err := ql.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+
+			resp, err := next(req)
+			if err != nil {
+				return nil, err
+			}
+			// the request was already made and counted, so failing to record a reported
+			// block only costs the other processes an early request, not this response
+			ql.observe(resp)
+			return resp, nil
+		}
+	}
+}
+
+// Wait blocks until the ledger has a free slot (or ctx is done) and takes it
+//   ctx - cancels the wait
+func (ql *QuotaLedger) Wait(ctx context.Context) error {
+	for {
+		delay, err := ql.Reserve()
+		if err != nil || delay <= 0 {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Reserve takes a slot if one is free right now and returns 0; otherwise nothing is taken
+// and it returns how long until the next slot frees up
+func (ql *QuotaLedger) Reserve() (time.Duration, error) {
+	var delay time.Duration
+	err := ql.update(func(lf *ledgerFile, now time.Time) bool {
+		delay = ql.delay(lf, now)
+		if delay > 0 {
+			return false
+		}
+		lf.Requests = append(lf.Requests, now.UnixNano())
+		return true
+	})
+	return delay, err
+}
+
+// TimeUntilNextSlot returns how long until a request can be made without going over the quota
+// (0 if one can be made right now); unlike Reserve it does not take the slot
+func (ql *QuotaLedger) TimeUntilNextSlot() (time.Duration, error) {
+	var delay time.Duration
+	err := ql.update(func(lf *ledgerFile, now time.Time) bool {
+		delay = ql.delay(lf, now)
+		return false
+	})
+	return delay, err
+}
+
+// observe blocks the ledger until the reset time when a response reports the quota is exhausted
+func (ql *QuotaLedger) observe(resp *http.Response) error {
+	var blockedUntil time.Time
+	if rl, ok := parseRateLimit(resp.Header); ok && rl.Remaining <= 0 && !rl.Reset.IsZero() {
+		blockedUntil = rl.Reset
+	} else if resp.StatusCode == http.StatusTooManyRequests {
+		delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"))
+		if !ok {
+			delay = ql.window
+		}
+		blockedUntil = time.Now().Add(delay)
+	} else {
+		return nil
+	}
+
+	return ql.update(func(lf *ledgerFile, now time.Time) bool {
+		if blockedUntil.UnixNano() <= lf.BlockedUntil {
+			return false
+		}
+		lf.BlockedUntil = blockedUntil.UnixNano()
+		return true
+	})
+}
+
+// delay returns how long until the next slot frees up in lf, dropping the requests that left the window
+func (ql *QuotaLedger) delay(lf *ledgerFile, now time.Time) time.Duration {
+	cutoff := now.Add(-ql.window).UnixNano()
+	i := 0
+	for i < len(lf.Requests) && lf.Requests[i] <= cutoff {
+		i++
+	}
+	lf.Requests = lf.Requests[i:]
+
+	var delay time.Duration
+	if len(lf.Requests) >= ql.limit {
+		oldest := lf.Requests[len(lf.Requests)-ql.limit]
+		delay = time.Duration(oldest - cutoff)
+	}
+	if blocked := time.Duration(lf.BlockedUntil - now.UnixNano()); blocked > delay {
+		delay = blocked
+	}
+	return delay
+}
+
+// update reads the ledger file under the lock and calls fn with its content; if fn returns
+// true the (modified) content is written back before the lock is released
+func (ql *QuotaLedger) update(fn func(lf *ledgerFile, now time.Time) bool) error {
+	ql.mu.Lock()
+	defer ql.mu.Unlock()
+
+	f, err := os.OpenFile(ql.path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open quota ledger: %w", err)
+	}
+	defer f.Close()
+
+	unlock, err := lockFile(f)
+	if err != nil {
+		return fmt.Errorf("failed to lock quota ledger: %w", err)
+	}
+	defer unlock()
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("failed to read quota ledger: %w", err)
+	}
+	lf := ledgerFile{}
+	if len(b) > 0 {
+		// a corrupted ledger is started over rather than blocking every process
+		if err := json.Unmarshal(b, &lf); err != nil {
+			lf = ledgerFile{}
+		}
+	}
+
+	if !fn(&lf, time.Now()) {
+		return nil
+	}
+
+	b, err = json.Marshal(lf)
+	if err != nil {
+		return fmt.Errorf("failed to marshal quota ledger: %w", err)
+	}
+	if err := f.Truncate(0); err != nil {
+		return fmt.Errorf("failed to write quota ledger: %w", err)
+	}
+	if _, err := f.WriteAt(b, 0); err != nil {
+		return fmt.Errorf("failed to write quota ledger: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package lotrsdk
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+const (
+	lockFilePoll = 10 * time.Millisecond
+	// a lock file older than this was left behind by a process that died while holding it
+	lockFileStale = 10 * time.Second
+)
+
+// lockFile takes an exclusive lock on f by creating a sidecar f.Name()+".lock" file,
+// blocking until it is available; used on platforms without flock
+func lockFile(f *os.File) (func(), error) {
+	path := f.Name() + ".lock"
+	for {
+		lock, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			lock.Close()
+			return func() {
+				os.Remove(path)
+			}, nil
+		} else if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > lockFileStale {
+			os.Remove(path)
+			continue
+		}
+		time.Sleep(lockFilePoll)
+	}
+}
@@ -0,0 +1,150 @@
+package lotrsdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaLedgerShared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quota.json")
+	first, err := NewQuotaLedger(path, 3, time.Hour)
+	assert.Nil(t, err)
+	second, err := NewQuotaLedger(path, 3, time.Hour)
+	assert.Nil(t, err)
+
+	for _, ledger := range []*QuotaLedger{first, second, first} {
+		delay, err := ledger.Reserve()
+		assert.Nil(t, err)
+		assert.Equal(t, delay, time.Duration(0))
+	}
+
+	delay, err := second.Reserve()
+	assert.Nil(t, err)
+	assert.InDelta(t, float64(time.Hour), float64(delay), float64(time.Minute))
+
+	next, err := first.TimeUntilNextSlot()
+	assert.Nil(t, err)
+	assert.InDelta(t, float64(time.Hour), float64(next), float64(time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, first.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestQuotaLedgerConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quota.json")
+
+	const limit = 20
+	var mu sync.Mutex
+	granted := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		ledger, err := NewQuotaLedger(path, limit, time.Hour)
+		assert.Nil(t, err)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				delay, err := ledger.Reserve()
+				assert.Nil(t, err)
+				if delay == 0 {
+					mu.Lock()
+					granted++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, granted, limit)
+}
+
+// TestQuotaLedgerHelperProcess is not a real test; it is run as a separate process by
+// TestQuotaLedgerAcrossProcesses to take slots from the ledger in LOTRSDK_LEDGER
+func TestQuotaLedgerHelperProcess(t *testing.T) {
+	path := os.Getenv("LOTRSDK_LEDGER")
+	if path == "" {
+		t.Skip("only run as a helper process")
+	}
+	ledger, err := NewQuotaLedger(path, 3, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := ledger.Reserve(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestQuotaLedgerAcrossProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quota.json")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestQuotaLedgerHelperProcess$")
+	cmd.Env = append(os.Environ(), "LOTRSDK_LEDGER="+path)
+	out, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(out))
+
+	ledger, err := NewQuotaLedger(path, 3, time.Hour)
+	assert.Nil(t, err)
+	delay, err := ledger.Reserve()
+	assert.Nil(t, err)
+	assert.Equal(t, delay, time.Duration(0))
+	delay, err = ledger.Reserve()
+	assert.Nil(t, err)
+	assert.Greater(t, delay, time.Duration(0))
+}
+
+func TestQuotaLedgerMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+	ledger, err := NewQuotaLedger(filepath.Join(t.TempDir(), "quota.json"), 100, time.Hour)
+	assert.Nil(t, err)
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithQuotaLedger(ledger))
+
+	_, _, err = client.Books()
+	assert.NotNil(t, err)
+
+	// the 429 blocks every process sharing the ledger until the Retry-After
+	next, err := ledger.TimeUntilNextSlot()
+	assert.Nil(t, err)
+	assert.InDelta(t, float64(time.Hour), float64(next), float64(time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = client.Books(WithContext(ctx))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestQuotaLedgerMiddlewareKeepsResponse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quota.json")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the ledger can no longer be written once the request has been sent
+		os.Remove(path)
+		os.Mkdir(path, 0o755)
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "4102444800")
+		w.Write([]byte(bookData))
+	}))
+	defer ts.Close()
+	ledger, err := NewQuotaLedger(path, 100, time.Hour)
+	assert.Nil(t, err)
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithQuotaLedger(ledger))
+
+	books, _, err := client.Books()
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+}
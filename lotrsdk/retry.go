@@ -0,0 +1,140 @@
+package lotrsdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// NoRetries is the RetryPolicy.MaxRetries that turns retrying off (a zero MaxRetries uses the default)
+const NoRetries = -1
+
+// RetryPolicy controls how RetryMiddleware retries failed requests
+// any zero field uses its default
+type RetryPolicy struct {
+	// MaxRetries is the number of times a request is retried after the first attempt; defaults to 3.
+	// Use NoRetries to never retry
+	MaxRetries int
+	// MinBackoff is the delay before the first retry; it doubles for every retry after. Defaults to 500ms
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between retries, including delays asked for by a Retry-After header.
+	// Defaults to 30s
+	MaxBackoff time.Duration
+}
+
+// RetryMiddleware retries requests that failed with a network error, a 429 (Too Many Requests)
+// or a 502, 503 or 504 status, waiting with exponential backoff in between. A Retry-After
+// header sent by the server is honored. Retries stop early if the request's context is done.
+//   policy - how many times to retry and how long to wait
+func RetryMiddleware(policy RetryPolicy) Middleware {
+	switch {
+	case policy.MaxRetries < 0:
+		policy.MaxRetries = 0
+	case policy.MaxRetries == 0:
+		policy.MaxRetries = defaultMaxRetries
+	}
+	if policy.MinBackoff <= 0 {
+		policy.MinBackoff = defaultMinBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultMaxBackoff
+	}
+
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			state := getRequestState(req.Context())
+			for attempt := 0; ; attempt++ {
+				resp, err := next(req)
+				if attempt >= policy.MaxRetries || !shouldRetry(req.Context(), resp, err) {
+					return resp, err
+				}
+
+				delay := policy.backoff(attempt, resp)
+				if resp != nil {
+					// drain the body so the connection can be reused
+					io.Copy(io.Discard, resp.Body)
+					resp.Body.Close()
+				}
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-req.Context().Done():
+					timer.Stop()
+					return nil, req.Context().Err()
+				case <-timer.C:
+				}
+
+				if state != nil {
+					state.retries++
+				}
+			}
+		}
+	}
+}
+
+// shouldRetry reports whether the outcome of an attempt is worth retrying
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// errors caused by our own context being done will not get better
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns how long to wait before the retry following the provided attempt
+func (rp RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if delay > rp.MaxBackoff {
+				return rp.MaxBackoff
+			}
+			return delay
+		}
+	}
+
+	delay := rp.MinBackoff << attempt
+	if delay <= 0 || delay > rp.MaxBackoff {
+		delay = rp.MaxBackoff
+	}
+	// add up to 25% jitter so that many clients do not retry in lockstep, without going past MaxBackoff
+	delay += time.Duration(rand.Int63n(int64(delay)/4 + 1))
+	if delay > rp.MaxBackoff {
+		delay = rp.MaxBackoff
+	}
+	return delay
+}
+
+// parseRetryAfter parses a Retry-After header, which is either a number of seconds or an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
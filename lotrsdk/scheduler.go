@@ -0,0 +1,250 @@
+package lotrsdk
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultAgingSlots is how many request slots a queued request waits before it gains a priority level
+const defaultAgingSlots = 10
+
+// Priority is how urgently a request should be sent when a Scheduler has several queued
+type Priority int
+
+const (
+	PriorityLow    Priority = iota
+	PriorityNormal Priority = iota
+	PriorityHigh   Priority = iota
+)
+
+// WithPriority sets the priority of the call's requests; calls without it use the priority
+// set with ContextWithPriority, or PriorityNormal
+//   priority - the priority of the call
+func WithPriority(priority Priority) Filter {
+	return callOption(func(cfg *callConfig) {
+		cfg.priority = &priority
+	})
+}
+
+type priorityKey struct{}
+
+// ContextWithPriority returns a context that gives every call made with it (through WithContext
+// or the fan-out methods) the provided priority
+//   ctx - the parent context
+//   priority - the priority of the calls
+func ContextWithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// priorityFromContext returns the priority set with ContextWithPriority, or PriorityNormal
+func priorityFromContext(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return priority
+	}
+	return PriorityNormal
+}
+
+// SchedulerOptions configures a Scheduler
+type SchedulerOptions struct {
+	// Requests and Interval are the pace requests are sent at (ie, 100 requests per 10 minutes)
+	Requests int
+	Interval time.Duration
+	// Burst is how many requests can be sent back to back after the Scheduler has been idle; defaults to 1
+	Burst int
+	// Aging is how long a queued request waits before it is treated as one priority level higher,
+	// so that low priority requests are never starved; defaults to the time of 10 requests
+	Aging time.Duration
+}
+
+// Scheduler paces outgoing requests to a fixed rate, and when several requests are waiting
+// sends the highest priority one first. A request's priority rises the longer it waits, so
+// background work still makes progress while interactive requests keep arriving.
+// A single Scheduler should be shared by every Client using the same token.
+type Scheduler struct {
+	mu sync.Mutex
+
+	every time.Duration
+	burst float64
+	aging time.Duration
+
+	// tokens is the number of requests that can be sent right now, as of last
+	tokens float64
+	last   time.Time
+
+	queue []*scheduledRequest
+	seq   uint64
+	timer *time.Timer
+}
+
+type scheduledRequest struct {
+	priority Priority
+	enqueued time.Time
+	seq      uint64
+	// ready is closed once the request may be sent
+	ready chan struct{}
+}
+
+// NewScheduler creates a Scheduler; if Requests or Interval is not positive the Scheduler
+// does not pace requests (but still serves queued requests by priority)
+//   opts - the pace and fairness settings
+func NewScheduler(opts SchedulerOptions) *Scheduler {
+	burst := opts.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+	s := &Scheduler{
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+	if opts.Requests > 0 && opts.Interval > 0 {
+		s.every = opts.Interval / time.Duration(opts.Requests)
+	}
+	s.aging = opts.Aging
+	if s.aging <= 0 {
+		s.aging = defaultAgingSlots * s.every
+	}
+	return s
+}
+
+// WithScheduler adds scheduler.Middleware() to the Client's request chain
+//   scheduler - the Scheduler shared by every Client using the token
+func WithScheduler(scheduler *Scheduler) ClientOption {
+	return WithMiddleware(scheduler.Middleware())
+}
+
+// Middleware returns a Middleware that waits for the Scheduler to let each request through
+func (s *Scheduler) Middleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			priority := priorityFromContext(req.Context())
+			if state := getRequestState(req.Context()); state != nil {
+				priority = state.priority
+			}
+			if err := s.Wait(req.Context(), priority); err != nil {
+				return nil, err
+			}
+			return next(req)
+		}
+	}
+}
+
+// Wait blocks until the Scheduler lets a request with the provided priority through, or ctx is done
+//   ctx - cancels the wait
+//   priority - the priority of the request
+func (s *Scheduler) Wait(ctx context.Context, priority Priority) error {
+	s.mu.Lock()
+	s.refillLocked(time.Now())
+	if len(s.queue) == 0 && s.takeLocked() {
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.seq++
+	sr := &scheduledRequest{
+		priority: priority,
+		enqueued: time.Now(),
+		seq:      s.seq,
+		ready:    make(chan struct{}),
+	}
+	s.queue = append(s.queue, sr)
+	s.scheduleLocked()
+	s.mu.Unlock()
+
+	select {
+	case <-sr.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, queued := range s.queue {
+		if queued == sr {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return ctx.Err()
+		}
+	}
+	// the request was let through just as ctx was done; hand its slot back
+	s.tokens++
+	if s.tokens > s.burst {
+		s.tokens = s.burst
+	}
+	s.scheduleLocked()
+	return ctx.Err()
+}
+
+// refillLocked adds the tokens earned since last
+func (s *Scheduler) refillLocked(now time.Time) {
+	if s.every <= 0 {
+		s.tokens = s.burst
+	} else {
+		s.tokens += float64(now.Sub(s.last)) / float64(s.every)
+		if s.tokens > s.burst {
+			s.tokens = s.burst
+		}
+	}
+	s.last = now
+}
+
+// takeLocked uses up a token if one is available
+func (s *Scheduler) takeLocked() bool {
+	if s.tokens < 1 {
+		return false
+	}
+	s.tokens--
+	return true
+}
+
+// scheduleLocked makes sure dispatch runs once the next token is available
+func (s *Scheduler) scheduleLocked() {
+	if s.timer != nil || len(s.queue) == 0 {
+		return
+	}
+	delay := time.Duration(0)
+	if s.tokens < 1 {
+		delay = time.Duration((1 - s.tokens) * float64(s.every))
+	}
+	s.timer = time.AfterFunc(delay, s.dispatch)
+}
+
+// dispatch lets through as many queued requests as there are tokens, best first
+func (s *Scheduler) dispatch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.timer = nil
+	now := time.Now()
+	s.refillLocked(now)
+	for len(s.queue) > 0 && s.takeLocked() {
+		best := 0
+		for i := 1; i < len(s.queue); i++ {
+			if s.before(s.queue[i], s.queue[best], now) {
+				best = i
+			}
+		}
+		close(s.queue[best].ready)
+		s.queue = append(s.queue[:best], s.queue[best+1:]...)
+	}
+	s.scheduleLocked()
+}
+
+// before reports whether a should be sent before b: the higher effective priority (its priority
+// plus one level for every Aging it has waited) goes first, and ties go to the oldest
+func (s *Scheduler) before(a, b *scheduledRequest, now time.Time) bool {
+	ea, eb := s.effectivePriority(a, now), s.effectivePriority(b, now)
+	if ea != eb {
+		return ea > eb
+	}
+	return a.seq < b.seq
+}
+
+func (s *Scheduler) effectivePriority(sr *scheduledRequest, now time.Time) int64 {
+	effective := int64(sr.priority)
+	if s.aging > 0 {
+		effective += int64(now.Sub(sr.enqueued) / s.aging)
+	}
+	return effective
+}
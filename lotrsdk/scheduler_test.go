@@ -0,0 +1,145 @@
+package lotrsdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// queueRequests starts a Wait for each priority (in order, so they are queued in that order)
+// and returns the order in which they were let through
+func queueRequests(s *Scheduler, priorities []Priority) []int {
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	for i, priority := range priorities {
+		i, priority := i, priority
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Wait(context.Background(), priority)
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}()
+		// make sure the requests are queued in order
+		for {
+			s.mu.Lock()
+			queued := len(s.queue)
+			s.mu.Unlock()
+			if queued > i {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	wg.Wait()
+	return order
+}
+
+func TestSchedulerPriority(t *testing.T) {
+	s := NewScheduler(SchedulerOptions{Requests: 1, Interval: 20 * time.Millisecond, Aging: time.Hour})
+	// use up the burst so everything after is queued
+	assert.Nil(t, s.Wait(context.Background(), PriorityNormal))
+
+	order := queueRequests(s, []Priority{PriorityLow, PriorityLow, PriorityNormal, PriorityHigh})
+
+	assert.Equal(t, order, []int{3, 2, 0, 1})
+}
+
+func TestSchedulerAging(t *testing.T) {
+	s := NewScheduler(SchedulerOptions{Requests: 1, Interval: 100 * time.Millisecond, Aging: 5 * time.Millisecond})
+	assert.Nil(t, s.Wait(context.Background(), PriorityNormal))
+
+	var mu sync.Mutex
+	var order []Priority
+	wait := func(priority Priority) {
+		s.Wait(context.Background(), priority)
+		mu.Lock()
+		order = append(order, priority)
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		wait(PriorityLow)
+	}()
+	// by the time the slot frees up the low priority request has waited 3 agings longer,
+	// which is enough to overtake the high priority request that arrived later
+	time.Sleep(15 * time.Millisecond)
+	go func() {
+		defer wg.Done()
+		wait(PriorityHigh)
+	}()
+	wg.Wait()
+
+	assert.Equal(t, order, []Priority{PriorityLow, PriorityHigh})
+}
+
+func TestSchedulerPacing(t *testing.T) {
+	s := NewScheduler(SchedulerOptions{Requests: 2, Interval: 40 * time.Millisecond, Burst: 2})
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		assert.Nil(t, s.Wait(context.Background(), PriorityNormal))
+	}
+
+	// 2 burst, then 4 more at one per 20ms
+	assert.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	s := NewScheduler(SchedulerOptions{Requests: 1, Interval: time.Hour})
+	assert.Nil(t, s.Wait(context.Background(), PriorityNormal))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, s.Wait(ctx, PriorityHigh), context.DeadlineExceeded)
+	assert.Equal(t, len(s.queue), 0)
+}
+
+func TestSchedulerMiddleware(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Write([]byte(bookData))
+	}))
+	defer ts.Close()
+	s := NewScheduler(SchedulerOptions{Requests: 1, Interval: 20 * time.Millisecond, Aging: time.Hour})
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithScheduler(s))
+	assert.Nil(t, s.Wait(context.Background(), PriorityNormal))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		client.Quotes(WithContext(ContextWithPriority(context.Background(), PriorityLow)))
+	}()
+	for {
+		s.mu.Lock()
+		queued := len(s.queue)
+		s.mu.Unlock()
+		if queued == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	go func() {
+		defer wg.Done()
+		client.Characters(WithPriority(PriorityHigh))
+	}()
+	wg.Wait()
+
+	assert.Equal(t, paths, []string{"/character", "/quote"})
+}
@@ -0,0 +1,851 @@
+package lotrsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// this file contains the full-text search index over quotes, which answers word, phrase and boolean
+// queries locally with BM25 ranking instead of regex filters against the API
+
+// ErrInvalidQuery is returned (wrapped) by QuoteIndex.Search for a query that can not be parsed
+var ErrInvalidQuery = errors.New("invalid search query")
+
+const (
+	// quoteIndexVersion is the version of the file written by QuoteIndex.Save
+	quoteIndexVersion = 1
+	// defaultQuoteResults is the number of results Search returns when no Limit is set
+	defaultQuoteResults = 10
+	// defaultHighlight surrounds the matched words of a snippet when no Highlight is set
+	defaultHighlight = "**"
+	// maxQuoteSnippetLength is roughly the longest snippet, in bytes, before highlighting
+	maxQuoteSnippetLength = 160
+
+	// the BM25 parameters: how quickly repeating a word stops adding to the score, and how much
+	// the length of a quote counts against it
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// QuoteSearchOptions narrows and formats the results of QuoteIndex.Search
+type QuoteSearchOptions struct {
+	// Limit is the maximum number of results; defaults to 10
+	Limit int
+	// Movies, if any, only selects the quotes from one of these movies
+	Movies []MovieID
+	// Characters, if any, only selects the quotes spoken by one of these characters
+	Characters []CharacterID
+	// HighlightStart and HighlightEnd surround the matched words of each Snippet; both default to "**"
+	HighlightStart string
+	HighlightEnd   string
+}
+
+// QuoteResult is a quote found by QuoteIndex.Search
+type QuoteResult struct {
+	Quote Quote
+	// Score is the BM25 relevance of the quote; only the order of scores within a search is meaningful
+	Score float64
+	// Snippet is the matching part of the dialog, with the matched words highlighted
+	Snippet string
+}
+
+// QuoteIndexChanges counts the quotes changed by QuoteIndex.Sync
+type QuoteIndexChanges struct {
+	Added   int
+	Updated int
+	Removed int
+}
+
+// QuoteIndex is a full-text search index over quotes' dialog; it is safe for concurrent use
+// words are matched regardless of case, accents and simple endings ("hobbits" matches "hobbit")
+type QuoteIndex struct {
+	mu sync.RWMutex
+	// docs are the indexed quotes by internal number, which postings refer to
+	docs    map[int]*quoteDoc
+	byID    map[QuoteID]int
+	nextDoc int
+	// postings are the positions of each term in each quote that contains it
+	postings    map[string]map[int][]int
+	totalLength int
+}
+
+type quoteDoc struct {
+	quote  Quote
+	length int
+}
+
+// quoteIndexFile is what QuoteIndex.Save writes; the postings are rebuilt when loading
+type quoteIndexFile struct {
+	Version int     `json:"version"`
+	Quotes  []Quote `json:"quotes"`
+}
+
+// NewQuoteIndex returns an index of the provided quotes; more can be added later
+//   quotes - the quotes to index, typically every one from Client.Quotes
+func NewQuoteIndex(quotes ...Quote) *QuoteIndex {
+	qi := &QuoteIndex{
+		docs:     make(map[int]*quoteDoc),
+		byID:     make(map[QuoteID]int),
+		postings: make(map[string]map[int][]int),
+	}
+	qi.Add(quotes...)
+	return qi
+}
+
+// LoadQuoteIndex reads an index written by QuoteIndex.Save
+//   path - the file to read
+func LoadQuoteIndex(path string) (*QuoteIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quote index: %w", err)
+	}
+	var file quoteIndexFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse quote index %s: %w", path, err)
+	}
+	if file.Version != quoteIndexVersion {
+		return nil, fmt.Errorf("unsupported quote index version %d in %s", file.Version, path)
+	}
+	return NewQuoteIndex(file.Quotes...), nil
+}
+
+// Save writes the index to path, replacing the file only once it is completely written
+//   path - the file to write; its directory is created if needed
+func (qi *QuoteIndex) Save(path string) error {
+	qi.mu.RLock()
+	file := quoteIndexFile{Version: quoteIndexVersion, Quotes: make([]Quote, 0, len(qi.docs))}
+	for _, doc := range qi.docs {
+		file.Quotes = append(file.Quotes, doc.quote)
+	}
+	qi.mu.RUnlock()
+	sort.Slice(file.Quotes, func(i, j int) bool {
+		return file.Quotes[i].ID < file.Quotes[j].ID
+	})
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to marshal quote index: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create quote index directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write quote index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write quote index: %w", err)
+	}
+	return nil
+}
+
+// Len returns the number of quotes in the index
+func (qi *QuoteIndex) Len() int {
+	qi.mu.RLock()
+	defer qi.mu.RUnlock()
+	return len(qi.docs)
+}
+
+// Add indexes quotes, replacing any already indexed with the same ID
+//   quotes - the quotes to index
+func (qi *QuoteIndex) Add(quotes ...Quote) {
+	qi.mu.Lock()
+	defer qi.mu.Unlock()
+	for _, quote := range quotes {
+		qi.addLocked(quote)
+	}
+}
+
+// Remove removes the quotes with the provided IDs, and returns how many were indexed
+//   ids - the IDs of the quotes to remove
+func (qi *QuoteIndex) Remove(ids ...QuoteID) int {
+	qi.mu.Lock()
+	defer qi.mu.Unlock()
+	removed := 0
+	for _, id := range ids {
+		if n, ok := qi.byID[id]; ok {
+			qi.removeLocked(n)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Sync fetches every quote and updates the index to match: new quotes are added, changed ones
+// replaced, and ones no longer returned removed. The index is left as it was if the fetch fails.
+//   ctx - cancels fetching the quotes
+//   c - the Client to fetch the quotes with
+func (qi *QuoteIndex) Sync(ctx context.Context, c Client) (QuoteIndexChanges, error) {
+	quotes, err := allPages(ctx, tracerOf(c), "Quotes", indexPageSize, c.Quotes)
+	if err != nil {
+		return QuoteIndexChanges{}, fmt.Errorf("syncing the quote index failed: %w", err)
+	}
+
+	qi.mu.Lock()
+	defer qi.mu.Unlock()
+	var changes QuoteIndexChanges
+	seen := make(map[QuoteID]bool, len(quotes))
+	for _, quote := range quotes {
+		seen[quote.ID] = true
+		if n, ok := qi.byID[quote.ID]; !ok {
+			changes.Added++
+		} else if qi.docs[n].quote != quote {
+			changes.Updated++
+		} else {
+			continue
+		}
+		qi.addLocked(quote)
+	}
+	for id, n := range qi.byID {
+		if !seen[id] {
+			qi.removeLocked(n)
+			changes.Removed++
+		}
+	}
+	return changes, nil
+}
+
+func (qi *QuoteIndex) addLocked(quote Quote) {
+	if n, ok := qi.byID[quote.ID]; ok {
+		qi.removeLocked(n)
+	}
+	n := qi.nextDoc
+	qi.nextDoc++
+
+	tokens := tokenizeText(quote.Dialog)
+	qi.docs[n] = &quoteDoc{quote: quote, length: len(tokens)}
+	qi.byID[quote.ID] = n
+	qi.totalLength += len(tokens)
+	for position, token := range tokens {
+		docs, ok := qi.postings[token.term]
+		if !ok {
+			docs = make(map[int][]int)
+			qi.postings[token.term] = docs
+		}
+		docs[n] = append(docs[n], position)
+	}
+}
+
+func (qi *QuoteIndex) removeLocked(n int) {
+	doc := qi.docs[n]
+	for _, token := range tokenizeText(doc.quote.Dialog) {
+		if docs, ok := qi.postings[token.term]; ok {
+			delete(docs, n)
+			if len(docs) == 0 {
+				delete(qi.postings, token.term)
+			}
+		}
+	}
+	qi.totalLength -= doc.length
+	delete(qi.byID, doc.quote.ID)
+	delete(qi.docs, n)
+}
+
+// Search returns the quotes matching query, most relevant first
+//
+// A query is made of words, which must all appear ("ring precious"), and "quoted phrases", whose words
+// must appear together in order. OR matches either side ("gollum OR smeagol"), NOT or a leading - leaves
+// out quotes that match ("ring -precious"), and parentheses group ("(frodo OR sam) AND ring"); AND is
+// optional. Operators must be upper case; lower case "or" is just a word.
+//   query - what to search for
+//   opts - filters, the number of results, and how to highlight the snippets
+func (qi *QuoteIndex) Search(query string, opts QuoteSearchOptions) ([]QuoteResult, error) {
+	node, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultQuoteResults
+	}
+	if opts.HighlightStart == "" && opts.HighlightEnd == "" {
+		opts.HighlightStart, opts.HighlightEnd = defaultHighlight, defaultHighlight
+	}
+	terms := make(map[string]bool)
+	node.terms(true, terms)
+
+	qi.mu.RLock()
+	defer qi.mu.RUnlock()
+	results := make([]QuoteResult, 0)
+	for n := range node.match(qi) {
+		doc := qi.docs[n]
+		if !matchesIDs(doc.quote.Movie, opts.Movies) || !matchesIDs(doc.quote.Character, opts.Characters) {
+			continue
+		}
+		results = append(results, QuoteResult{Quote: doc.quote, Score: qi.score(n, terms)})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Quote.ID < results[j].Quote.ID
+	})
+	if len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	for i := range results {
+		results[i].Snippet = highlightSnippet(results[i].Quote.Dialog, terms, opts.HighlightStart, opts.HighlightEnd)
+	}
+	return results, nil
+}
+
+// score returns the BM25 score of quote n for terms
+func (qi *QuoteIndex) score(n int, terms map[string]bool) float64 {
+	docCount := float64(len(qi.docs))
+	averageLength := float64(qi.totalLength) / docCount
+	length := float64(qi.docs[n].length)
+
+	var score float64
+	for term := range terms {
+		docs := qi.postings[term]
+		frequency := float64(len(docs[n]))
+		if frequency == 0 {
+			continue
+		}
+		df := float64(len(docs))
+		idf := math.Log(1 + (docCount-df+0.5)/(df+0.5))
+		norm := 1 - bm25B
+		if averageLength > 0 {
+			norm += bm25B * length / averageLength
+		}
+		score += idf * frequency * (bm25K1 + 1) / (frequency + bm25K1*norm)
+	}
+	return score
+}
+
+// all returns every indexed quote
+func (qi *QuoteIndex) all() map[int]bool {
+	docs := make(map[int]bool, len(qi.docs))
+	for n := range qi.docs {
+		docs[n] = true
+	}
+	return docs
+}
+
+func matchesIDs[ID comparable](id ID, ids []ID) bool {
+	if len(ids) == 0 {
+		return true
+	}
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// textToken is a term of a text and where it is in the text
+type textToken struct {
+	term string
+	// start and end are the byte offsets of the word in the text
+	start, end int
+}
+
+// tokenizeText splits text into words of letters and digits, which are lower cased, stripped of
+// accents and apostrophes ("Théoden's" is "theodens") and stemmed (see stem)
+func tokenizeText(text string) []textToken {
+	tokens := make([]textToken, 0)
+	var word strings.Builder
+	start, end := -1, 0
+	flush := func() {
+		if start >= 0 {
+			tokens = append(tokens, textToken{term: stem(word.String()), start: start, end: end})
+		}
+		word.Reset()
+		start = -1
+	}
+
+	for i, r := range text {
+		lower := unicode.ToLower(r)
+		if folded, ok := accentFolds[lower]; ok {
+			lower = folded
+		}
+		switch {
+		case unicode.IsLetter(lower) || unicode.IsDigit(lower):
+			if start < 0 {
+				start = i
+			}
+			word.WriteRune(lower)
+			end = i + utf8.RuneLen(r)
+		case lower == '\'' && start >= 0:
+			// an apostrophe within a word joins it
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// stem strips the most common English endings from a lower case word, so that "hobbits", "hobbit",
+// "killed" and "kill" are the same term; it is deliberately simple rather than linguistically correct
+func stem(word string) string {
+	// plurals are stripped first, so that "families" and "family" go on to lose the same ending
+	n := len(word)
+	switch {
+	case n > 4 && strings.HasSuffix(word, "ies"):
+		word = word[:n-3] + "y"
+	case n > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") &&
+		!strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		word = word[:n-1]
+	}
+
+	n = len(word)
+	switch {
+	case n > 5 && strings.HasSuffix(word, "ing"):
+		word = undouble(word[:n-3])
+	case n > 4 && strings.HasSuffix(word, "ed"):
+		word = undouble(word[:n-2])
+	case n > 4 && strings.HasSuffix(word, "ly"):
+		word = word[:n-2]
+	}
+	// "love", "loved" and "loving" all end up as "lov"
+	if len(word) > 3 && strings.HasSuffix(word, "e") {
+		word = word[:len(word)-1]
+	}
+	return word
+}
+
+// undouble drops the last letter of a word ending in a doubled consonant ("stopp" is "stop"),
+// except for the letters that are commonly doubled anyway ("kill", "pass", "buzz")
+func undouble(word string) string {
+	n := len(word)
+	if n > 2 && word[n-1] == word[n-2] && !strings.ContainsRune("aeiouylsz", rune(word[n-1])) {
+		return word[:n-1]
+	}
+	return word
+}
+
+// highlightSnippet returns the part of dialog around the first matched term, at most roughly
+// maxQuoteSnippetLength long, with every matched term surrounded by start and end
+func highlightSnippet(dialog string, terms map[string]bool, start, end string) string {
+	tokens := tokenizeText(dialog)
+	if len(tokens) == 0 {
+		return strings.Join(strings.Fields(dialog), " ")
+	}
+
+	first := 0
+	for i, token := range tokens {
+		if terms[token.term] {
+			first = i
+			break
+		}
+	}
+	// start a third of a snippet before the first match, then take as many words as fit
+	from := first
+	for from > 0 && tokens[first].end-tokens[from-1].start <= maxQuoteSnippetLength/3 {
+		from--
+	}
+	to := first
+	for to < len(tokens)-1 && tokens[to+1].end-tokens[from].start <= maxQuoteSnippetLength {
+		to++
+	}
+
+	// the whole dialog is kept, including its punctuation, when it fits
+	textStart, textEnd := tokens[from].start, tokens[to].end
+	if from == 0 {
+		textStart = 0
+	}
+	if to == len(tokens)-1 {
+		textEnd = len(dialog)
+	}
+
+	var b strings.Builder
+	if textStart > 0 {
+		b.WriteString("...")
+	}
+	position := textStart
+	for _, token := range tokens[from : to+1] {
+		if !terms[token.term] {
+			continue
+		}
+		b.WriteString(dialog[position:token.start])
+		b.WriteString(start)
+		b.WriteString(dialog[token.start:token.end])
+		b.WriteString(end)
+		position = token.end
+	}
+	b.WriteString(dialog[position:textEnd])
+	if textEnd < len(dialog) {
+		b.WriteString("...")
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// queryNode is a parsed search query, or part of one
+type queryNode interface {
+	// match returns the quotes matching the node
+	match(qi *QuoteIndex) map[int]bool
+	// terms adds the terms that count towards the score to into; negated terms do not
+	terms(positive bool, into map[string]bool)
+}
+
+// termNode matches the quotes containing a term
+type termNode struct {
+	term string
+}
+
+func (tn termNode) match(qi *QuoteIndex) map[int]bool {
+	docs := make(map[int]bool, len(qi.postings[tn.term]))
+	for n := range qi.postings[tn.term] {
+		docs[n] = true
+	}
+	return docs
+}
+
+func (tn termNode) terms(positive bool, into map[string]bool) {
+	if positive {
+		into[tn.term] = true
+	}
+}
+
+// phraseNode matches the quotes containing its terms next to each other, in order
+type phraseNode struct {
+	phrase []string
+}
+
+func (pn phraseNode) match(qi *QuoteIndex) map[int]bool {
+	docs := make(map[int]bool)
+	for n, positions := range qi.postings[pn.phrase[0]] {
+		for _, position := range positions {
+			if pn.at(qi, n, position) {
+				docs[n] = true
+				break
+			}
+		}
+	}
+	return docs
+}
+
+// at reports whether the phrase is in quote n starting at position
+func (pn phraseNode) at(qi *QuoteIndex, n, position int) bool {
+	for offset, term := range pn.phrase[1:] {
+		found := false
+		for _, p := range qi.postings[term][n] {
+			if p == position+offset+1 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (pn phraseNode) terms(positive bool, into map[string]bool) {
+	if positive {
+		for _, term := range pn.phrase {
+			into[term] = true
+		}
+	}
+}
+
+// andNode matches the quotes matching all of its nodes
+type andNode struct {
+	nodes []queryNode
+}
+
+func (an andNode) match(qi *QuoteIndex) map[int]bool {
+	docs := an.nodes[0].match(qi)
+	for _, node := range an.nodes[1:] {
+		other := node.match(qi)
+		for n := range docs {
+			if !other[n] {
+				delete(docs, n)
+			}
+		}
+	}
+	return docs
+}
+
+func (an andNode) terms(positive bool, into map[string]bool) {
+	for _, node := range an.nodes {
+		node.terms(positive, into)
+	}
+}
+
+// orNode matches the quotes matching any of its nodes
+type orNode struct {
+	nodes []queryNode
+}
+
+func (on orNode) match(qi *QuoteIndex) map[int]bool {
+	docs := make(map[int]bool)
+	for _, node := range on.nodes {
+		for n := range node.match(qi) {
+			docs[n] = true
+		}
+	}
+	return docs
+}
+
+func (on orNode) terms(positive bool, into map[string]bool) {
+	for _, node := range on.nodes {
+		node.terms(positive, into)
+	}
+}
+
+// notNode matches the quotes not matching its node
+type notNode struct {
+	node queryNode
+}
+
+func (nn notNode) match(qi *QuoteIndex) map[int]bool {
+	docs := qi.all()
+	for n := range nn.node.match(qi) {
+		delete(docs, n)
+	}
+	return docs
+}
+
+func (nn notNode) terms(positive bool, into map[string]bool) {
+	nn.node.terms(!positive, into)
+}
+
+// queryTokenKind is the kind of a lexical token of a search query
+type queryTokenKind int
+
+const (
+	queryWord queryTokenKind = iota
+	queryPhrase
+	queryAnd
+	queryOr
+	queryNot
+	queryOpen
+	queryClose
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+}
+
+// lexQuery splits a search query into words, phrases, operators and parentheses
+func lexQuery(query string) ([]queryToken, error) {
+	tokens := make([]queryToken, 0)
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: queryOpen})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: queryClose})
+			i++
+		case c == '-' && (i == 0 || strings.ContainsRune(" \t\n\r(", rune(query[i-1]))):
+			tokens = append(tokens, queryToken{kind: queryNot})
+			i++
+		case c == '"':
+			end := strings.IndexByte(query[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated phrase at %d", ErrInvalidQuery, i)
+			}
+			tokens = append(tokens, queryToken{kind: queryPhrase, text: query[i+1 : i+1+end]})
+			i += end + 2
+		default:
+			end := strings.IndexAny(query[i:], " \t\n\r()\"")
+			if end < 0 {
+				end = len(query) - i
+			}
+			word := query[i : i+end]
+			switch word {
+			case "AND":
+				tokens = append(tokens, queryToken{kind: queryAnd})
+			case "OR":
+				tokens = append(tokens, queryToken{kind: queryOr})
+			case "NOT":
+				tokens = append(tokens, queryToken{kind: queryNot})
+			default:
+				tokens = append(tokens, queryToken{kind: queryWord, text: word})
+			}
+			i += end
+		}
+	}
+	return tokens, nil
+}
+
+// parseQuery parses a search query (see QuoteIndex.Search)
+// OR binds loosest, then AND (written or implied), then NOT
+func parseQuery(query string) (queryNode, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	parser := &queryParser{tokens: tokens}
+	if len(parser.tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty query", ErrInvalidQuery)
+	}
+	node, err := parser.or()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(parser.tokens) {
+		return nil, fmt.Errorf("%w: unexpected )", ErrInvalidQuery)
+	}
+	if node == nil {
+		return nil, fmt.Errorf("%w: no words to search for", ErrInvalidQuery)
+	}
+	return node, nil
+}
+
+// queryParser is a recursive descent parser over the tokens of a query
+// its methods return a nil node for parts with no searchable words, such as punctuation
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (qp *queryParser) peek() (queryToken, bool) {
+	if qp.pos >= len(qp.tokens) {
+		return queryToken{}, false
+	}
+	return qp.tokens[qp.pos], true
+}
+
+func (qp *queryParser) or() (queryNode, error) {
+	nodes := make([]queryNode, 0, 1)
+	for {
+		node, err := qp.and()
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+		if token, ok := qp.peek(); !ok || token.kind != queryOr {
+			break
+		}
+		qp.pos++
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, nil
+	case 1:
+		return nodes[0], nil
+	}
+	return orNode{nodes: nodes}, nil
+}
+
+func (qp *queryParser) and() (queryNode, error) {
+	nodes := make([]queryNode, 0, 1)
+	for {
+		token, ok := qp.peek()
+		if !ok || token.kind == queryOr || token.kind == queryClose {
+			if len(nodes) == 0 && !qp.afterOperand() {
+				return nil, fmt.Errorf("%w: missing words before %s", ErrInvalidQuery, qp.describe())
+			}
+			break
+		}
+		if token.kind == queryAnd {
+			if len(nodes) == 0 {
+				return nil, fmt.Errorf("%w: AND with nothing before it", ErrInvalidQuery)
+			}
+			qp.pos++
+			if next, ok := qp.peek(); !ok || next.kind == queryOr || next.kind == queryClose || next.kind == queryAnd {
+				return nil, fmt.Errorf("%w: AND with nothing after it", ErrInvalidQuery)
+			}
+			continue
+		}
+		node, err := qp.unary()
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+
+	switch len(nodes) {
+	case 0:
+		return nil, nil
+	case 1:
+		return nodes[0], nil
+	}
+	return andNode{nodes: nodes}, nil
+}
+
+// afterOperand reports whether the token before the current one could end an operand; an OR, an
+// open parenthesis or the start of the query can not, so nothing follows them yet
+func (qp *queryParser) afterOperand() bool {
+	if qp.pos == 0 {
+		return false
+	}
+	kind := qp.tokens[qp.pos-1].kind
+	return kind == queryWord || kind == queryPhrase || kind == queryClose
+}
+
+func (qp *queryParser) describe() string {
+	token, ok := qp.peek()
+	switch {
+	case !ok:
+		return "the end of the query"
+	case token.kind == queryOr:
+		return "OR"
+	}
+	return ")"
+}
+
+func (qp *queryParser) unary() (queryNode, error) {
+	token := qp.tokens[qp.pos]
+	qp.pos++
+	switch token.kind {
+	case queryNot:
+		if next, ok := qp.peek(); !ok || next.kind == queryOr || next.kind == queryClose || next.kind == queryAnd {
+			return nil, fmt.Errorf("%w: NOT with nothing after it", ErrInvalidQuery)
+		}
+		node, err := qp.unary()
+		if err != nil || node == nil {
+			return nil, err
+		}
+		return notNode{node: node}, nil
+	case queryOpen:
+		node, err := qp.or()
+		if err != nil {
+			return nil, err
+		}
+		if next, ok := qp.peek(); !ok || next.kind != queryClose {
+			return nil, fmt.Errorf("%w: missing )", ErrInvalidQuery)
+		}
+		qp.pos++
+		return node, nil
+	case queryPhrase:
+		if strings.TrimSpace(token.text) == "" {
+			return nil, fmt.Errorf("%w: empty phrase", ErrInvalidQuery)
+		}
+		return wordsNode(token.text), nil
+	}
+	// a word with punctuation inside ("gil-galad") is searched as a phrase
+	return wordsNode(token.text), nil
+}
+
+// wordsNode returns a node matching text: a term for a single word, a phrase for several, and nil
+// if text has no words
+func wordsNode(text string) queryNode {
+	tokens := tokenizeText(text)
+	switch len(tokens) {
+	case 0:
+		return nil
+	case 1:
+		return termNode{term: tokens[0].term}
+	}
+	phrase := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		phrase = append(phrase, token.term)
+	}
+	return phraseNode{phrase: phrase}
+}
@@ -0,0 +1,245 @@
+package lotrsdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	fellowshipMovie = MovieID("5cd95395de30eff6ebccde5c")
+	towersMovie     = MovieID("5cd95395de30eff6ebccde5b")
+	frodoCharacter  = CharacterID("5cd99d4bde30eff6ebccfc15")
+	gollumCharacter = CharacterID("5cd99d4bde30eff6ebccfe9e")
+	gandalfID       = CharacterID("5cd99d4bde30eff6ebccfea0")
+)
+
+var searchQuotes = []Quote{
+	{ID: "000000000000000000000001", Dialog: "One Ring to rule them all.", Movie: fellowshipMovie, Character: gandalfID},
+	{ID: "000000000000000000000002", Dialog: "My precious. My precious!", Movie: towersMovie, Character: gollumCharacter},
+	{ID: "000000000000000000000003", Dialog: "I wish the Ring had never come to me.", Movie: fellowshipMovie, Character: frodoCharacter},
+	{ID: "000000000000000000000004", Dialog: "You shall not pass!", Movie: fellowshipMovie, Character: gandalfID},
+	{ID: "000000000000000000000005", Dialog: "Stupid fat hobbit! It's ours, precious, the ring is ours.", Movie: towersMovie, Character: gollumCharacter},
+	{ID: "000000000000000000000006", Dialog: "The hobbits are going to Isengard.", Movie: towersMovie, Character: frodoCharacter},
+	{ID: "000000000000000000000007", Dialog: "Théoden King stands alone.", Movie: towersMovie, Character: gandalfID},
+}
+
+func resultIDs(results []QuoteResult) []QuoteID {
+	ids := make([]QuoteID, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.Quote.ID)
+	}
+	return ids
+}
+
+func TestQuoteIndexSearch(t *testing.T) {
+	index := NewQuoteIndex(searchQuotes...)
+	assert.Equal(t, index.Len(), len(searchQuotes))
+
+	tests := []struct {
+		query    string
+		expected []QuoteID
+	}{
+		{"precious", []QuoteID{"000000000000000000000002", "000000000000000000000005"}},
+		{"RING precious", []QuoteID{"000000000000000000000005"}},
+		{"ring AND precious", []QuoteID{"000000000000000000000005"}},
+		{"hobbit", []QuoteID{"000000000000000000000005", "000000000000000000000006"}},
+		{"\"shall not pass\"", []QuoteID{"000000000000000000000004"}},
+		{"\"not shall pass\"", []QuoteID{}},
+		{"pass OR isengard", []QuoteID{"000000000000000000000004", "000000000000000000000006"}},
+		{"ring -precious", []QuoteID{"000000000000000000000001", "000000000000000000000003"}},
+		{"ring NOT precious", []QuoteID{"000000000000000000000001", "000000000000000000000003"}},
+		{"(pass OR wish) AND ring", []QuoteID{"000000000000000000000003"}},
+		{"theoden", []QuoteID{"000000000000000000000007"}},
+		{"mordor", []QuoteID{}},
+	}
+	for _, test := range tests {
+		results, err := index.Search(test.query, QuoteSearchOptions{})
+		assert.Nil(t, err, test.query)
+		assert.ElementsMatch(t, resultIDs(results), test.expected, test.query)
+	}
+}
+
+func TestQuoteIndexRanking(t *testing.T) {
+	index := NewQuoteIndex(searchQuotes...)
+
+	// the quote repeating precious, and shorter, ranks first
+	results, err := index.Search("precious", QuoteSearchOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, resultIDs(results), []QuoteID{"000000000000000000000002", "000000000000000000000005"})
+	assert.Greater(t, results[0].Score, results[1].Score)
+
+	// the rarer word counts for more
+	results, err = index.Search("ring OR isengard", QuoteSearchOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, results[0].Quote.ID, QuoteID("000000000000000000000006"))
+
+	results, err = index.Search("ring OR precious", QuoteSearchOptions{Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, resultIDs(results), []QuoteID{"000000000000000000000002", "000000000000000000000005"})
+}
+
+func TestQuoteIndexFilters(t *testing.T) {
+	index := NewQuoteIndex(searchQuotes...)
+
+	results, err := index.Search("ring", QuoteSearchOptions{Movies: []MovieID{fellowshipMovie}})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, resultIDs(results), []QuoteID{"000000000000000000000001", "000000000000000000000003"})
+
+	results, err = index.Search("ring", QuoteSearchOptions{Characters: []CharacterID{gollumCharacter, frodoCharacter}})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, resultIDs(results), []QuoteID{"000000000000000000000003", "000000000000000000000005"})
+
+	results, err = index.Search("ring", QuoteSearchOptions{Movies: []MovieID{towersMovie}, Characters: []CharacterID{frodoCharacter}})
+	assert.Nil(t, err)
+	assert.Equal(t, len(results), 0)
+}
+
+func TestQuoteIndexSnippet(t *testing.T) {
+	index := NewQuoteIndex(searchQuotes...)
+
+	results, err := index.Search("\"shall not pass\"", QuoteSearchOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, results[0].Snippet, "You **shall** **not** **pass**!")
+
+	results, err = index.Search("hobbits", QuoteSearchOptions{Characters: []CharacterID{gollumCharacter}, HighlightStart: "<b>", HighlightEnd: "</b>"})
+	assert.Nil(t, err)
+	assert.Equal(t, results[0].Snippet, "Stupid fat <b>hobbit</b>! It's ours, precious, the ring is ours.")
+
+	// negated words are not highlighted
+	results, err = index.Search("ring -precious", QuoteSearchOptions{Characters: []CharacterID{gandalfID}})
+	assert.Nil(t, err)
+	assert.Equal(t, results[0].Snippet, "One **Ring** to rule them all.")
+
+	long := strings.Repeat("and on and on ", 30) + "the ring " + strings.Repeat("went on and on ", 30)
+	snippet := highlightSnippet(long, map[string]bool{"ring": true}, "[", "]")
+	assert.True(t, strings.HasPrefix(snippet, "..."))
+	assert.True(t, strings.HasSuffix(snippet, "..."))
+	assert.Contains(t, snippet, "the [ring] went")
+	assert.LessOrEqual(t, len(snippet), maxQuoteSnippetLength+10)
+}
+
+func TestQuoteIndexInvalidQuery(t *testing.T) {
+	index := NewQuoteIndex(searchQuotes...)
+	for _, query := range []string{"", "   ", "\"ring", "ring OR", "OR ring", "AND ring", "ring AND", "(ring", "ring)", "()", "ring -", "NOT", "\"\"", "!!!"} {
+		_, err := index.Search(query, QuoteSearchOptions{})
+		assert.True(t, errors.Is(err, ErrInvalidQuery), query)
+	}
+}
+
+func TestTokenizeText(t *testing.T) {
+	terms := func(text string) []string {
+		result := make([]string, 0)
+		for _, token := range tokenizeText(text) {
+			result = append(result, token.term)
+		}
+		return result
+	}
+	assert.Equal(t, terms("Théoden's hobbits, KILLED!"), []string{"theoden", "hobbit", "kill"})
+	assert.Equal(t, terms("It’s stopped being loved"), []string{"its", "stop", "being", "lov"})
+	assert.Equal(t, terms("precious pass ladies"), []string{"precious", "pass", "lady"})
+	// singular and plural forms are the same term
+	assert.Equal(t, terms("family families morning mornings string strings"), []string{"fami", "fami", "morn", "morn", "str", "str"})
+	assert.Equal(t, terms("hundred hundreds wedding weddings"), []string{"hundr", "hundr", "wed", "wed"})
+	// short words are left alone
+	assert.Equal(t, terms("was his sing"), []string{"was", "his", "sing"})
+
+	tokens := tokenizeText("  Éowyn, go!")
+	assert.Equal(t, tokens[0], textToken{term: "eowyn", start: 2, end: 8})
+	assert.Equal(t, tokens[1], textToken{term: "go", start: 10, end: 12})
+}
+
+func TestQuoteIndexSearchPlural(t *testing.T) {
+	index := NewQuoteIndex(Quote{ID: "000000000000000000000008", Dialog: "My family is gone."})
+	results, _ := index.Search("families", QuoteSearchOptions{})
+	assert.Equal(t, len(results), 1)
+	results, _ = index.Search("weddings OR mornings", QuoteSearchOptions{})
+	assert.Equal(t, len(results), 0)
+}
+
+func TestQuoteIndexIncremental(t *testing.T) {
+	index := NewQuoteIndex(searchQuotes[:3]...)
+
+	index.Add(searchQuotes[3])
+	results, _ := index.Search("pass", QuoteSearchOptions{})
+	assert.Equal(t, len(results), 1)
+
+	// replacing a quote drops its old words
+	index.Add(Quote{ID: searchQuotes[3].ID, Dialog: "Fly, you fools!"})
+	results, _ = index.Search("pass", QuoteSearchOptions{})
+	assert.Equal(t, len(results), 0)
+	results, _ = index.Search("fools", QuoteSearchOptions{})
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, index.Len(), 4)
+
+	assert.Equal(t, index.Remove(searchQuotes[0].ID, "missing"), 1)
+	results, _ = index.Search("ring", QuoteSearchOptions{})
+	assert.Equal(t, resultIDs(results), []QuoteID{searchQuotes[2].ID})
+	assert.Equal(t, index.Len(), 3)
+}
+
+func TestQuoteIndexSaveLoad(t *testing.T) {
+	index := NewQuoteIndex(searchQuotes...)
+	path := filepath.Join(t.TempDir(), "index", "quotes.json")
+	assert.Nil(t, index.Save(path))
+
+	loaded, err := LoadQuoteIndex(path)
+	assert.Nil(t, err)
+	assert.Equal(t, loaded.Len(), index.Len())
+	expected, _ := index.Search("ring OR hobbit", QuoteSearchOptions{})
+	actual, _ := loaded.Search("ring OR hobbit", QuoteSearchOptions{})
+	assert.Equal(t, actual, expected)
+
+	_, err = LoadQuoteIndex(filepath.Join(t.TempDir(), "missing.json"))
+	assert.NotNil(t, err)
+}
+
+// newQuotePagesServer serves quotes pageSize at a time and counts the requests
+func newQuotePagesServer(quotes *[]Quote, requests *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(requests, 1)
+		docs, _ := json.Marshal(*quotes)
+		fmt.Fprintf(w, `{"docs":%s,"total":%d,"limit":1000,"offset":0,"page":1,"pages":1}`, docs, len(*quotes))
+	}))
+}
+
+func TestQuoteIndexSync(t *testing.T) {
+	var requests int64
+	quotes := append([]Quote(nil), searchQuotes[:4]...)
+	ts := newQuotePagesServer(&quotes, &requests)
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL))
+
+	index := NewQuoteIndex()
+	changes, err := index.Sync(context.Background(), client)
+	assert.Nil(t, err)
+	assert.Equal(t, changes, QuoteIndexChanges{Added: 4})
+
+	quotes = append([]Quote{{ID: quotes[0].ID, Dialog: "One Ring to find them."}}, quotes[2:]...)
+	quotes = append(quotes, searchQuotes[4])
+	changes, err = index.Sync(context.Background(), client)
+	assert.Nil(t, err)
+	assert.Equal(t, changes, QuoteIndexChanges{Added: 1, Updated: 1, Removed: 1})
+	assert.Equal(t, index.Len(), 4)
+	results, _ := index.Search("find", QuoteSearchOptions{})
+	assert.Equal(t, len(results), 1)
+
+	changes, err = index.Sync(context.Background(), client)
+	assert.Nil(t, err)
+	assert.Equal(t, changes, QuoteIndexChanges{})
+	assert.Equal(t, atomic.LoadInt64(&requests), int64(3))
+
+	ts.Close()
+	_, err = index.Sync(context.Background(), client)
+	assert.NotNil(t, err)
+	assert.Equal(t, index.Len(), 4)
+}
@@ -0,0 +1,226 @@
+package lotrsdk
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrNoConversion is returned when converting a year to or from the Years of the Trees, which were
+// counted in longer Valian years and so do not line up with the ages of the Sun
+var ErrNoConversion = errors.New("cannot convert to or from the Years of the Trees")
+
+// ageOffsets is the number of years of the Sun between the start of the First Age and the start of each age
+var ageOffsets = map[Age]int{
+	FirstAge:  0,
+	SecondAge: ageLengths[FirstAge],
+	ThirdAge:  ageLengths[FirstAge] + ageLengths[SecondAge],
+	FourthAge: ageLengths[FirstAge] + ageLengths[SecondAge] + ageLengths[ThirdAge],
+}
+
+// NewMiddleEarthDate returns the exact date of year in age, such as NewMiddleEarthDate(ThirdAge, 3019)
+//   age - the age of the year
+//   year - the year within the age
+func NewMiddleEarthDate(age Age, year int) MiddleEarthDate {
+	return MiddleEarthDate{Age: age, Year: year, HasYear: true}
+}
+
+// ConvertYear expresses a year of one age as a year of another; for instance TA 1 is SA 3442, and FO 1 is TA 3022
+// the result may be past the end of the target age or negative, which still counts years correctly
+//   year - the year within from
+//   from - the age of year
+//   to - the age to express the year in
+func ConvertYear(year int, from, to Age) (int, error) {
+	fromOffset, ok := ageOffsets[from]
+	if !ok {
+		return 0, fmt.Errorf("%w: from %s", ErrNoConversion, from)
+	}
+	toOffset, ok := ageOffsets[to]
+	if !ok {
+		return 0, fmt.Errorf("%w: to %s", ErrNoConversion, to)
+	}
+	return year + fromOffset - toOffset, nil
+}
+
+// YearsBetween returns roughly how many years passed from a to b (negative if b is before a)
+// dates with no year are placed by their qualifier within their age
+//   a - the earlier date
+//   b - the later date
+func YearsBetween(a, b MiddleEarthDate) (int, error) {
+	if a.Age == b.Age {
+		return roundYears(b.position() - a.position()), nil
+	}
+	aOffset, aOK := ageOffsets[a.Age]
+	bOffset, bOK := ageOffsets[b.Age]
+	if !aOK || !bOK {
+		return 0, ErrNoConversion
+	}
+	return roundYears(float64(bOffset) + b.position() - float64(aOffset) - a.position()), nil
+}
+
+func roundYears(years float64) int {
+	if years < 0 {
+		return -int(-years + 0.5)
+	}
+	return int(years + 0.5)
+}
+
+// EventKind is what happened in a TimelineEvent
+type EventKind int
+
+const (
+	EventBirth EventKind = iota
+	EventDeath
+)
+
+func (ek EventKind) String() string {
+	if ek == EventDeath {
+		return "death"
+	}
+	return "birth"
+}
+
+// TimelineEvent is the birth or death of a character
+type TimelineEvent struct {
+	Kind      EventKind
+	Date      MiddleEarthDate
+	Character Character
+}
+
+// Lifespan is the known part of a character's life
+type Lifespan struct {
+	Character Character
+	Birth     Optional[MiddleEarthDate]
+	Death     Optional[MiddleEarthDate]
+}
+
+// Years returns roughly how many years the character lived; false if the birth or death is unknown
+// or in the Years of the Trees
+func (l Lifespan) Years() (int, bool) {
+	if !l.Birth.Valid || !l.Death.Valid {
+		return 0, false
+	}
+	years, err := YearsBetween(l.Birth.Value, l.Death.Value)
+	return years, err == nil
+}
+
+// AliveAt reports whether the character was alive at date: born on or before it, and died on or after it
+// a character whose death is unknown is not known to be alive (see PossiblyAliveAt)
+//   date - the date of interest
+func (l Lifespan) AliveAt(date MiddleEarthDate) bool {
+	return l.Birth.Valid && l.Death.Valid && l.Birth.Value.Compare(date) <= 0 && l.Death.Value.Compare(date) >= 0
+}
+
+// PossiblyAliveAt reports whether nothing known rules out the character being alive at date
+// at least one of the birth and death must be known
+//   date - the date of interest
+func (l Lifespan) PossiblyAliveAt(date MiddleEarthDate) bool {
+	if !l.Birth.Valid && !l.Death.Valid {
+		return false
+	}
+	if l.Birth.Valid && l.Birth.Value.Compare(date) > 0 {
+		return false
+	}
+	return !l.Death.Valid || l.Death.Value.Compare(date) >= 0
+}
+
+// overlaps reports whether any known part of the lifespan is within age
+func (l Lifespan) overlaps(age Age) bool {
+	start, end := l.Birth, l.Death
+	if !start.Valid {
+		start = end
+	}
+	if !end.Valid {
+		end = start
+	}
+	return start.Value.Age <= age && end.Value.Age >= age
+}
+
+// Timeline is the births and deaths of a set of characters, in order
+type Timeline struct {
+	// Events are every birth and death that could be parsed, in order; births come before deaths at the same date
+	Events []TimelineEvent
+	// Lifespans are the characters with a birth or death that could be parsed, in the order they were provided
+	Lifespans []Lifespan
+	// Undated are the characters with neither a birth nor a death that could be parsed
+	Undated []Character
+}
+
+// BuildTimeline parses the Birth and Death of every character (see ParseMiddleEarthDate) into a Timeline
+//   characters - the characters, typically from Client.Characters
+func BuildTimeline(characters []Character) *Timeline {
+	timeline := &Timeline{}
+	for _, character := range characters {
+		lifespan := Lifespan{Character: character}
+		if birth, err := character.BirthDate(); err == nil {
+			lifespan.Birth = Some(birth)
+			timeline.Events = append(timeline.Events, TimelineEvent{Kind: EventBirth, Date: birth, Character: character})
+		}
+		if death, err := character.DeathDate(); err == nil {
+			lifespan.Death = Some(death)
+			timeline.Events = append(timeline.Events, TimelineEvent{Kind: EventDeath, Date: death, Character: character})
+		}
+
+		if lifespan.Birth.Valid || lifespan.Death.Valid {
+			timeline.Lifespans = append(timeline.Lifespans, lifespan)
+		} else {
+			timeline.Undated = append(timeline.Undated, character)
+		}
+	}
+
+	sort.SliceStable(timeline.Events, func(i, j int) bool {
+		a, b := timeline.Events[i], timeline.Events[j]
+		if cmp := a.Date.Compare(b.Date); cmp != 0 {
+			return cmp < 0
+		}
+		return a.Kind < b.Kind
+	})
+	return timeline
+}
+
+// AliveAt returns the characters known to be alive at date, such as NewMiddleEarthDate(ThirdAge, 3019)
+//   date - the date of interest
+func (t *Timeline) AliveAt(date MiddleEarthDate) []Lifespan {
+	return t.filter(func(l Lifespan) bool {
+		return l.AliveAt(date)
+	})
+}
+
+// PossiblyAliveAt returns the characters who may have been alive at date, including those whose birth or death is unknown
+//   date - the date of interest
+func (t *Timeline) PossiblyAliveAt(date MiddleEarthDate) []Lifespan {
+	return t.filter(func(l Lifespan) bool {
+		return l.PossiblyAliveAt(date)
+	})
+}
+
+// InAge returns the characters whose known lifespan overlaps age
+//   age - the age of interest
+func (t *Timeline) InAge(age Age) []Lifespan {
+	return t.filter(func(l Lifespan) bool {
+		return l.overlaps(age)
+	})
+}
+
+// Between returns the events from one date to another, both included
+//   from - the first date
+//   to - the last date
+func (t *Timeline) Between(from, to MiddleEarthDate) []TimelineEvent {
+	events := make([]TimelineEvent, 0)
+	for _, event := range t.Events {
+		if event.Date.Compare(from) >= 0 && event.Date.Compare(to) <= 0 {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (t *Timeline) filter(keep func(Lifespan) bool) []Lifespan {
+	lifespans := make([]Lifespan, 0)
+	for _, lifespan := range t.Lifespans {
+		if keep(lifespan) {
+			lifespans = append(lifespans, lifespan)
+		}
+	}
+	return lifespans
+}
@@ -0,0 +1,116 @@
+package lotrsdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var timelineCharacters = []Character{
+	{Name: "Aragorn II Elessar", Birth: "March 1 ,TA 2931", Death: "FO 120"},
+	{Name: "Boromir", Birth: "TA 2978", Death: "February 26 ,3019"},
+	{Name: "Théoden", Birth: "TA 2948", Death: "March 15 ,TA 3019"},
+	{Name: "Isildur", Birth: "SA 3209", Death: "TA 2"},
+	{Name: "Lúthien", Birth: "YT 1200", Death: "FA 503"},
+	{Name: "Beren", Birth: "FA 432", Death: "FA 503"},
+	{Name: "Gollum", Birth: "Late ,Third Age", Death: "TA 3019"},
+	{Name: "Gimli", Birth: "TA 2879", Death: ""},
+	{Name: "Tom Bombadil", Birth: "NaN", Death: "Unknown"},
+}
+
+func names(lifespans []Lifespan) []string {
+	result := make([]string, len(lifespans))
+	for i, lifespan := range lifespans {
+		result[i] = lifespan.Character.Name
+	}
+	return result
+}
+
+func TestConvertYear(t *testing.T) {
+	tests := []struct {
+		year     int
+		from, to Age
+		expected int
+	}{
+		{1, ThirdAge, SecondAge, 3442},
+		{3442, SecondAge, ThirdAge, 1},
+		{1, FourthAge, ThirdAge, 3022},
+		{3019, ThirdAge, FourthAge, -2},
+		{500, FirstAge, ThirdAge, 500 - 590 - 3441},
+		{3019, ThirdAge, ThirdAge, 3019},
+	}
+	for _, test := range tests {
+		year, err := ConvertYear(test.year, test.from, test.to)
+		assert.Nil(t, err)
+		assert.Equal(t, year, test.expected)
+	}
+
+	_, err := ConvertYear(1200, YearsOfTheTrees, FirstAge)
+	assert.ErrorIs(t, err, ErrNoConversion)
+}
+
+func TestYearsBetween(t *testing.T) {
+	years, err := YearsBetween(NewMiddleEarthDate(ThirdAge, 2931), NewMiddleEarthDate(FourthAge, 120))
+	assert.Nil(t, err)
+	assert.Equal(t, years, 210)
+
+	years, err = YearsBetween(NewMiddleEarthDate(SecondAge, 3209), NewMiddleEarthDate(ThirdAge, 2))
+	assert.Nil(t, err)
+	assert.Equal(t, years, 234)
+
+	years, err = YearsBetween(NewMiddleEarthDate(ThirdAge, 3019), NewMiddleEarthDate(ThirdAge, 2931))
+	assert.Nil(t, err)
+	assert.Equal(t, years, -88)
+
+	_, err = YearsBetween(NewMiddleEarthDate(YearsOfTheTrees, 1200), NewMiddleEarthDate(FirstAge, 503))
+	assert.ErrorIs(t, err, ErrNoConversion)
+}
+
+func TestBuildTimeline(t *testing.T) {
+	timeline := BuildTimeline(timelineCharacters)
+
+	// Boromir's death has no age, and Tom Bombadil has no dates at all
+	assert.Equal(t, len(timeline.Lifespans), 8)
+	assert.Equal(t, len(timeline.Undated), 1)
+	assert.Equal(t, timeline.Undated[0].Name, "Tom Bombadil")
+
+	for i := 1; i < len(timeline.Events); i++ {
+		assert.False(t, timeline.Events[i].Date.Before(timeline.Events[i-1].Date))
+	}
+	first := timeline.Events[0]
+	assert.Equal(t, first.Character.Name, "Lúthien")
+	assert.Equal(t, first.Kind, EventBirth)
+	last := timeline.Events[len(timeline.Events)-1]
+	assert.Equal(t, last.Character.Name, "Aragorn II Elessar")
+	assert.Equal(t, last.Kind, EventDeath)
+
+	aragorn := timeline.Lifespans[0]
+	years, ok := aragorn.Years()
+	assert.True(t, ok)
+	assert.Equal(t, years, 210)
+	_, ok = timeline.Lifespans[7].Years()
+	assert.False(t, ok)
+}
+
+func TestTimelineAliveAt(t *testing.T) {
+	timeline := BuildTimeline(timelineCharacters)
+	warOfTheRing := NewMiddleEarthDate(ThirdAge, 3019)
+
+	assert.Equal(t, names(timeline.AliveAt(warOfTheRing)), []string{"Aragorn II Elessar", "Théoden", "Gollum"})
+	assert.Equal(t, names(timeline.PossiblyAliveAt(warOfTheRing)), []string{"Aragorn II Elessar", "Boromir", "Théoden", "Gollum", "Gimli"})
+	assert.Equal(t, names(timeline.AliveAt(NewMiddleEarthDate(FirstAge, 450))), []string{"Lúthien", "Beren"})
+	assert.Empty(t, timeline.AliveAt(NewMiddleEarthDate(SecondAge, 100)))
+}
+
+func TestTimelineQueries(t *testing.T) {
+	timeline := BuildTimeline(timelineCharacters)
+
+	assert.Equal(t, names(timeline.InAge(SecondAge)), []string{"Isildur"})
+	assert.Equal(t, names(timeline.InAge(FourthAge)), []string{"Aragorn II Elessar"})
+
+	events := timeline.Between(NewMiddleEarthDate(ThirdAge, 2900), NewMiddleEarthDate(ThirdAge, 2950))
+	assert.Equal(t, len(events), 2)
+	for _, event := range events {
+		assert.Equal(t, event.Kind, EventBirth)
+	}
+}
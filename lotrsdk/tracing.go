@@ -0,0 +1,196 @@
+package lotrsdk
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Tracer starts the spans recorded by a Client; implement it (along with Span) to adapt
+// a tracing library such as OpenTelemetry. Every Client method opens a span named after
+// the method (ie, lotrsdk.Books), calls that fetch every page of a resource group the pages
+// under a span such as lotrsdk.AllCharacters, and every attempt at sending the request opens a child
+// span named lotrsdk.attempt, so retries show up as siblings under the method's span.
+type Tracer interface {
+	// Start opens a span as a child of any span in ctx, returning a context holding the new span
+	//   ctx - the parent context
+	//   name - the name of the span
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation
+// its methods may be called from several goroutines (the httptrace hooks run on the transport's goroutines)
+type Span interface {
+	// SetAttributes adds attributes to the span
+	SetAttributes(attrs ...Attribute)
+	// RecordError marks the span as failed
+	RecordError(err error)
+	// End closes the span
+	End()
+}
+
+// Attribute is a key-value pair attached to a Span
+// Value is one of string, int, bool or time.Duration
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// WithTracer makes the Client record its calls with the provided Tracer
+// without this option nothing is traced
+//   tracer - the Tracer to open spans with; nil turns tracing off
+func WithTracer(tracer Tracer) ClientOption {
+	return func(c *client) {
+		if tracer == nil {
+			tracer = noopTracer{}
+		}
+		c.tracer = tracer
+	}
+}
+
+// tracerOf returns the Tracer of a Client created by NewClient, and a no-op Tracer for any other
+func tracerOf(c Client) Tracer {
+	if cl, ok := c.(*client); ok {
+		return cl.tracer
+	}
+	return noopTracer{}
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+func (noopSpan) RecordError(err error)            {}
+func (noopSpan) End()                             {}
+
+// traceAttempt is the innermost middleware; it opens a span for every attempt at sending the request
+// and records the connection timings reported by net/http/httptrace on it
+func (c *client) traceAttempt(next RoundTripFunc) RoundTripFunc {
+	if _, ok := c.tracer.(noopTracer); ok {
+		return next
+	}
+
+	return func(req *http.Request) (*http.Response, error) {
+		ctx, span := c.tracer.Start(req.Context(), "lotrsdk.attempt")
+		defer span.End()
+
+		attrs := []Attribute{
+			{Key: "http.method", Value: req.Method},
+		}
+		if state := getRequestState(req.Context()); state != nil {
+			attrs = append(attrs, Attribute{Key: "lotrsdk.retry", Value: state.retries})
+		}
+		span.SetAttributes(attrs...)
+
+		timings := &attemptTimings{start: time.Now()}
+		resp, err := next(req.WithContext(httptrace.WithClientTrace(ctx, timings.clientTrace())))
+		span.SetAttributes(timings.attributes()...)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		span.SetAttributes(Attribute{Key: "http.status_code", Value: resp.StatusCode})
+		return resp, nil
+	}
+}
+
+// attemptTimings collects the httptrace timings of a single attempt
+type attemptTimings struct {
+	mu sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+
+	dns         time.Duration
+	connect     time.Duration
+	tls         time.Duration
+	firstByte   time.Duration
+	reused      bool
+	gotConn     bool
+	gotResponse bool
+}
+
+func (at *attemptTimings) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			at.mu.Lock()
+			defer at.mu.Unlock()
+			at.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			at.mu.Lock()
+			defer at.mu.Unlock()
+			at.dns = time.Since(at.dnsStart)
+		},
+		ConnectStart: func(network, addr string) {
+			at.mu.Lock()
+			defer at.mu.Unlock()
+			if at.connectStart.IsZero() {
+				at.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(network, addr string, err error) {
+			at.mu.Lock()
+			defer at.mu.Unlock()
+			if err == nil && at.connect == 0 {
+				at.connect = time.Since(at.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			at.mu.Lock()
+			defer at.mu.Unlock()
+			at.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			at.mu.Lock()
+			defer at.mu.Unlock()
+			at.tls = time.Since(at.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			at.mu.Lock()
+			defer at.mu.Unlock()
+			at.gotConn = true
+			at.reused = info.Reused
+		},
+		GotFirstResponseByte: func() {
+			at.mu.Lock()
+			defer at.mu.Unlock()
+			at.gotResponse = true
+			at.firstByte = time.Since(at.start)
+		},
+	}
+}
+
+// attributes returns the timings that were recorded (a reused connection has no dns, connect or tls timings)
+func (at *attemptTimings) attributes() []Attribute {
+	at.mu.Lock()
+	defer at.mu.Unlock()
+
+	var attrs []Attribute
+	if at.gotConn {
+		attrs = append(attrs, Attribute{Key: "http.conn.reused", Value: at.reused})
+	}
+	if at.dns > 0 {
+		attrs = append(attrs, Attribute{Key: "http.dns.duration", Value: at.dns})
+	}
+	if at.connect > 0 {
+		attrs = append(attrs, Attribute{Key: "http.connect.duration", Value: at.connect})
+	}
+	if at.tls > 0 {
+		attrs = append(attrs, Attribute{Key: "http.tls.duration", Value: at.tls})
+	}
+	if at.gotResponse {
+		attrs = append(attrs, Attribute{Key: "http.time_to_first_byte", Value: at.firstByte})
+	}
+	return attrs
+}
@@ -0,0 +1,180 @@
+package lotrsdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSpan struct {
+	mu     sync.Mutex
+	name   string
+	parent *testSpan
+	attrs  map[string]any
+	err    error
+	ended  bool
+}
+
+func (ts *testSpan) SetAttributes(attrs ...Attribute) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, attr := range attrs {
+		ts.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (ts *testSpan) RecordError(err error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.err = err
+}
+
+func (ts *testSpan) End() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.ended = true
+}
+
+type testSpanKey struct{}
+
+// testTracer records every span it starts, keeping track of parents through the context
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (tt *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(testSpanKey{}).(*testSpan)
+	span := &testSpan{name: name, parent: parent, attrs: map[string]any{}}
+	tt.mu.Lock()
+	tt.spans = append(tt.spans, span)
+	tt.mu.Unlock()
+	return context.WithValue(ctx, testSpanKey{}, span), span
+}
+
+func TestTracing(t *testing.T) {
+	var attempts int64
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(bookData))
+	}))
+	defer ts.Close()
+	tracer := &testTracer{}
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithHTTPClient(ts.Client()), WithTracer(tracer),
+		WithMiddleware(RetryMiddleware(RetryPolicy{MinBackoff: time.Millisecond})))
+
+	_, _, err := client.Books(BinaryFilter("name", FilterCompareEqual, "The Two Towers"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(tracer.spans), 3)
+	call, first, second := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+
+	assert.Equal(t, call.name, "lotrsdk.Books")
+	assert.Nil(t, call.parent)
+	assert.True(t, call.ended)
+	assert.Equal(t, call.attrs["lotrsdk.resource"], "book")
+	assert.Equal(t, call.attrs["lotrsdk.query"], "name=The+Two+Towers")
+	assert.Equal(t, call.attrs["lotrsdk.status.total"], 1)
+
+	assert.Equal(t, first.name, "lotrsdk.attempt")
+	assert.Equal(t, first.parent, call)
+	assert.Equal(t, first.attrs["lotrsdk.retry"], 0)
+	assert.Equal(t, first.attrs["http.status_code"], http.StatusServiceUnavailable)
+	assert.Equal(t, first.attrs["http.conn.reused"], false)
+	assert.Contains(t, first.attrs, "http.connect.duration")
+	assert.Contains(t, first.attrs, "http.tls.duration")
+	assert.Contains(t, first.attrs, "http.time_to_first_byte")
+
+	assert.Equal(t, second.parent, call)
+	assert.Equal(t, second.attrs["lotrsdk.retry"], 1)
+	assert.Equal(t, second.attrs["http.status_code"], http.StatusOK)
+	assert.Equal(t, second.attrs["http.conn.reused"], true)
+}
+
+func TestTracingFanOut(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"docs":[],"total":12,"limit":1,"offset":0,"page":1,"pages":12}`))
+	}))
+	defer ts.Close()
+	tracer := &testTracer{}
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithTracer(tracer))
+
+	results := client.CountQuotesForMovies(context.Background(), []Movie{{ID: "5cd95395de30eff6ebccde5b"}, {ID: "5cd95395de30eff6ebccde5c"}}, FanOutOptions{})
+
+	assert.Equal(t, results[0].Count, 12)
+	fanOutSpan := tracer.spans[0]
+	assert.Equal(t, fanOutSpan.name, "lotrsdk.CountQuotesForMovies")
+	var calls int
+	for _, span := range tracer.spans {
+		if span.name == "lotrsdk.CountQuoteFromMovie" {
+			calls++
+			assert.Equal(t, span.parent, fanOutSpan)
+			assert.Equal(t, span.attrs["lotrsdk.status.total"], 12)
+		}
+	}
+	assert.Equal(t, calls, 2)
+}
+
+func TestTracingRecordsErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	tracer := &testTracer{}
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithTracer(tracer))
+
+	_, _, err := client.Movies()
+
+	assert.NotNil(t, err)
+	assert.NotNil(t, tracer.spans[0].err)
+	assert.True(t, tracer.spans[0].ended)
+}
+
+func TestTracingPagination(t *testing.T) {
+	var requests int64
+	ts := newCharacterPagesServer(findCharacters, 5, &requests)
+	defer ts.Close()
+	tracer := &testTracer{}
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithTracer(tracer))
+
+	_, err := client.CharacterIndex(context.Background())
+	assert.Nil(t, err)
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	pagesSpan := tracer.spans[0]
+	assert.Equal(t, pagesSpan.name, "lotrsdk.AllCharacters")
+	assert.Nil(t, pagesSpan.parent)
+	assert.Equal(t, pagesSpan.attrs["lotrsdk.pagination.pages"], 3)
+	assert.Equal(t, pagesSpan.attrs["lotrsdk.status.total"], len(findCharacters))
+	assert.True(t, pagesSpan.ended)
+	var pages int
+	for _, span := range tracer.spans {
+		if span.name == "lotrsdk.Characters" {
+			pages++
+			assert.Equal(t, span.parent, pagesSpan)
+		}
+	}
+	assert.Equal(t, pages, 3)
+}
+
+func TestTracingNilTracer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(bookData))
+	}))
+	defer ts.Close()
+	client := NewClient("fake-token", WithBaseURL(ts.URL), WithTracer(nil))
+
+	books, _, err := client.Books()
+	assert.Nil(t, err)
+	assert.Equal(t, len(books), 1)
+}